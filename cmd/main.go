@@ -1,31 +1,223 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"crypto/rand"
+	"os"
+	"strings"
+	"time"
+
 	_ "music-library/docs" // Подключаем автоматически сгенерированные Swagger-документы
 
+	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"music-library/internal/api"
+	"music-library/internal/auth"
+	"music-library/internal/config"
 	"music-library/internal/database"
+	"music-library/internal/handlers"
 	"music-library/internal/logger"
 	"music-library/internal/middleware"
+	"music-library/internal/ratelimit"
+	"music-library/internal/repository"
+	"music-library/internal/services"
+	"music-library/migrations"
 )
 
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Access-токен из /auth/login или ключ API в виде "Bearer <token>"
+// @securityDefinitions.apikey  APIKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 Ключ API с нужной областью доступа
 func main() {
 	// Инициализация логгера
 	logger.Init()
 
-	// Подключение базы данных
-	database.ConnectDB()
-	defer database.DB.Close()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// STORAGE=memory позволяет поднять API без базы данных
+	var (
+		songRepo     repository.SongRepository
+		artistRepo   repository.ArtistRepository
+		albumRepo    repository.AlbumRepository
+		playlistRepo repository.PlaylistRepository
+		userRepo     repository.UserRepository
+		apiKeyRepo   repository.APIKeyRepository
+	)
+	if config.String("STORAGE", "postgres") == "memory" {
+		artists := repository.NewMemoryArtistRepository()
+		albums := repository.NewMemoryAlbumRepository(artists)
+		artistRepo = artists
+		albumRepo = albums
+		songs := repository.NewMemorySongRepository(artists, albums)
+		songRepo = songs
+		playlistRepo = repository.NewMemoryPlaylistRepository(songs)
+		users := repository.NewMemoryUserRepository()
+		userRepo = users
+		apiKeyRepo = repository.NewMemoryAPIKeyRepository(users)
+	} else {
+		// Подключение базы данных
+		database.ConnectDB()
+		defer database.DB.Close()
+		applyMigrations()
+		songRepo = repository.NewPostgresSongRepository(database.DB)
+		artistRepo = repository.NewPostgresArtistRepository(database.DB)
+		albumRepo = repository.NewPostgresAlbumRepository(database.DB)
+		playlistRepo = repository.NewPostgresPlaylistRepository(database.DB)
+		userRepo = repository.NewPostgresUserRepository(database.DB)
+		apiKeyRepo = repository.NewPostgresAPIKeyRepository(database.DB)
+	}
+
+	// Песни из корзины удаляются навсегда через TRASH_RETENTION_DAYS дней; 0 отключает очистку
+	if days := config.Int("TRASH_RETENTION_DAYS", 30); days > 0 {
+		retention := time.Duration(days) * 24 * time.Hour
+		interval := config.Duration("TRASH_PURGE_INTERVAL", time.Hour)
+		go services.NewTrashPurger(songRepo, retention, interval).Run(context.Background())
+	}
+
+	external, err := services.NewExternalClient(services.ExternalClientConfig{
+		BaseURL:    config.String("API_URL", ""),
+		Timeout:    config.Duration("EXTERNAL_API_TIMEOUT", 5*time.Second),
+		Retries:    config.Int("EXTERNAL_API_RETRIES", 2),
+		Backoff:    config.Duration("EXTERNAL_API_BACKOFF", 200*time.Millisecond),
+		MaxBackoff: config.Duration("EXTERNAL_API_MAX_BACKOFF", 2*time.Second),
+
+		BreakerThreshold: config.Int("EXTERNAL_API_BREAKER_THRESHOLD", 5),
+		BreakerCoolDown:  config.Duration("EXTERNAL_API_BREAKER_COOL_DOWN", 30*time.Second),
 
-	router := api.SetupRouter()
+		CacheSize:        config.Int("EXTERNAL_API_CACHE_SIZE", 10000),
+		CacheTTL:         config.Duration("EXTERNAL_API_CACHE_TTL", 24*time.Hour),
+		CacheNegativeTTL: config.Duration("EXTERNAL_API_CACHE_NEGATIVE_TTL", 10*time.Minute),
+	})
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to configure external API client")
+	}
+
+	enricher := services.NewEnricher(songRepo, albumRepo, external, services.EnricherConfig{
+		Workers:       config.Int("ENRICHMENT_WORKERS", 4),
+		QueueSize:     config.Int("ENRICHMENT_QUEUE_SIZE", 10000),
+		MaxAttempts:   config.Int("ENRICHMENT_MAX_ATTEMPTS", 5),
+		RetryDelay:    config.Duration("ENRICHMENT_RETRY_DELAY", 30*time.Second),
+		SweepInterval: config.Duration("ENRICHMENT_SWEEP_INTERVAL", 5*time.Minute),
+	})
+	go enricher.Run(context.Background())
+
+	tokens := auth.NewTokenService(
+		jwtSecret(),
+		config.Duration("JWT_ACCESS_TTL", 15*time.Minute),
+		config.Duration("JWT_REFRESH_TTL", 30*24*time.Hour),
+	)
+
+	// Неудачные попытки аутентификации расходуют ту же квоту IP-адреса,
+	// что и вход с регистрацией
+	authLimiter := rateLimiter("RATE_LIMIT_AUTH", "10/1m")
+	router := api.SetupRouter(api.Handlers{
+		Songs: handlers.NewSongHandler(songRepo, artistRepo, albumRepo, enricher, handlers.SongHandlerConfig{
+			RequireIfMatch: config.Bool("REQUIRE_IF_MATCH", false),
+			CacheMaxAge:    config.Duration("SONGS_CACHE_MAX_AGE", 0),
+			SaveUnenriched: config.Bool("EXTERNAL_API_SAVE_UNENRICHED", false),
+		}),
+		Artists:   handlers.NewArtistHandler(artistRepo, songRepo, albumRepo),
+		Albums:    handlers.NewAlbumHandler(albumRepo, artistRepo, songRepo),
+		Playlists: handlers.NewPlaylistHandler(playlistRepo, songRepo),
+		Auth:      handlers.NewAuthHandler(userRepo, tokens),
+		APIKeys:   handlers.NewAPIKeyHandler(apiKeyRepo),
+		Users:     handlers.NewUserHandler(userRepo),
+		Health:    handlers.NewHealthHandler(external),
+		Cache:     handlers.NewExternalCacheHandler(external.Cache()),
+	}, api.RateLimits{
+		Auth:     authLimiter,
+		Read:     rateLimiter("RATE_LIMIT_READ", "600/1m"),
+		Write:    rateLimiter("RATE_LIMIT_WRITE", "120/1m"),
+		External: rateLimiter("RATE_LIMIT_EXTERNAL", "10/1m"),
+	}, middleware.Authenticate(tokens, apiKeyRepo, userRepo, authLimiter))
+	// Анонимные квоты считаются по IP клиента, поэтому X-Forwarded-For
+	// принимается только от перечисленных прокси
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		logger.Log.WithError(err).Fatal("Invalid TRUSTED_PROXIES")
+	}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.Use(middleware.RequestLogger())
 
 	if err := router.Run(":8080"); err != nil {
-		fmt.Errorf("start router error")
+		logger.Log.WithError(err).Fatal("Failed to start router")
+	}
+}
+
+// rateLimiter создаёт ограничитель по квоте из переменной окружения name
+// вида "600/1m"; "off" отключает квоту. Некорректное значение логируется
+// и заменяется на def.
+func rateLimiter(name, def string) *ratelimit.Limiter {
+	limit, err := ratelimit.ParseLimit(config.String(name, def))
+	if err != nil {
+		logger.Log.WithError(err).WithFields(logrus.Fields{"name": name, "default": def}).Warn("Invalid rate limit, using default")
+		limit, _ = ratelimit.ParseLimit(def)
+	}
+	return ratelimit.New(limit)
+}
+
+// trustedProxies разбирает список адресов и подсетей доверенных прокси
+// из TRUSTED_PROXIES через запятую; пустой список отключает доверие
+func trustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(config.String("TRUSTED_PROXIES", ""), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// minJWTSecretLen — минимальная длина ключа подписи HS256 в байтах
+const minJWTSecretLen = 32
+
+// placeholderJWTSecrets — значения-заглушки из примеров конфигурации,
+// с которыми подпись токенов легко подделать
+var placeholderJWTSecrets = map[string]bool{
+	"change-me": true, "changeme": true, "secret": true, "jwt-secret": true,
+}
+
+// jwtSecret возвращает ключ подписи токенов из JWT_SECRET. Заглушка или
+// ключ короче minJWTSecretLen байт останавливают запуск. Если переменная
+// не задана, ключ генерируется при старте: токены перестают действовать
+// после перезапуска и не принимаются другими экземплярами сервиса.
+func jwtSecret() []byte {
+	if secret, ok := os.LookupEnv("JWT_SECRET"); ok {
+		if placeholderJWTSecrets[strings.ToLower(strings.TrimSpace(secret))] {
+			logger.Log.Fatal("JWT_SECRET is a placeholder value, set a random secret")
+		}
+		if len(secret) < minJWTSecretLen {
+			logger.Log.WithField("min_length", minJWTSecretLen).Fatal("JWT_SECRET is too short")
+		}
+		return []byte(secret)
+	}
+	logger.Log.Warn("JWT_SECRET is not set, using a random secret; tokens will not survive a restart")
+	secret := make([]byte, minJWTSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		logger.Log.WithError(err).Fatal("Failed to generate JWT secret")
+	}
+	return secret
+}
+
+// applyMigrations накатывает недостающие миграции при старте сервиса.
+// Отключается через AUTO_MIGRATE=false, если схемой управляют отдельно.
+func applyMigrations() {
+	if !config.Bool("AUTO_MIGRATE", true) {
+		return
+	}
+	migrator, err := database.NewMigrator(database.DB, migrations.FS)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to load migrations")
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		logger.Log.WithError(err).Fatal("Failed to apply migrations")
 	}
 }
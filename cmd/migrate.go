@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"music-library/internal/database"
+	"music-library/internal/logger"
+	"music-library/migrations"
+)
+
+// runMigrate обрабатывает подкоманду `migrate up|down|status`
+func runMigrate(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: main migrate up|down|status")
+		os.Exit(2)
+	}
+
+	database.ConnectDB()
+	defer database.DB.Close()
+
+	migrator, err := database.NewMigrator(database.DB, migrations.FS)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to load migrations")
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "status":
+		err = printMigrationStatus(ctx, migrator)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n", args[0])
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Migration failed")
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, st := range statuses {
+		status, appliedAt := "pending", "-"
+		if st.Applied {
+			status = "applied"
+			appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if st.Modified {
+			status = "modified"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", st.Version, st.Name, status, appliedAt)
+	}
+	return w.Flush()
+}
@@ -1,19 +1,151 @@
 package api
 
 import (
+	"net/http"
+
+	"music-library/internal/auth"
 	"music-library/internal/handlers"
+	"music-library/internal/middleware"
+	"music-library/internal/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
+// Handlers собирает обработчики всех ресурсов API
+type Handlers struct {
+	Songs     *handlers.SongHandler
+	Artists   *handlers.ArtistHandler
+	Albums    *handlers.AlbumHandler
+	Playlists *handlers.PlaylistHandler
+	Auth      *handlers.AuthHandler
+	APIKeys   *handlers.APIKeyHandler
+	Users     *handlers.UserHandler
+	Health    *handlers.HealthHandler
+	Cache     *handlers.ExternalCacheHandler
+}
+
+// RateLimits — квоты групп маршрутов; nil отключает квоту группы
+type RateLimits struct {
+	// Auth — вход, регистрация и обновление токенов, защита от подбора паролей.
+	// Тот же ограничитель стоит передать в middleware.Authenticate, чтобы
+	// неудачные проверки ключей и токенов расходовали ту же квоту.
+	Auth *ratelimit.Limiter
+	// Read — чтение библиотеки
+	Read *ratelimit.Limiter
+	// Write — изменения
+	Write *ratelimit.Limiter
+	// External — маршруты, которые обращаются к внешнему API за данными песен
+	External *ratelimit.Limiter
+}
+
+// externalRoutes обращаются к внешнему API и расходуют квоту External
+// вместо Write
+var externalRoutes = map[string]bool{
+	http.MethodPost + " /songs":        true,
+	http.MethodPost + " /songs/import": true,
+}
+
+// limiter возвращает middleware квоты группы, к которой относится маршрут
+func (l RateLimits) limiter(rt route) gin.HandlerFunc {
+	switch {
+	case externalRoutes[rt.method+" "+rt.path]:
+		return middleware.RateLimit("external", l.External)
+	case rt.method == http.MethodGet:
+		return middleware.RateLimit("read", l.Read)
+	default:
+		return middleware.RateLimit("write", l.Write)
+	}
+}
+
+// route — маршрут API и разрешение, которое он требует
+type route struct {
+	method     string
+	path       string
+	permission auth.Permission
+	handler    gin.HandlerFunc
+}
+
+// routes — таблица маршрутов с разрешениями. Какие роли имеют разрешение,
+// описано в auth; чтение песен и текстов доступно и без входа.
+func routes(h Handlers) []route {
+	return []route{
+		{http.MethodGet, "/api-keys", auth.PermKeysManage, h.APIKeys.GetAPIKeys},
+		{http.MethodPost, "/api-keys", auth.PermKeysManage, h.APIKeys.AddAPIKey},
+		{http.MethodPost, "/api-keys/:id/rotate", auth.PermKeysManage, h.APIKeys.RotateAPIKey},
+		{http.MethodDelete, "/api-keys/:id", auth.PermKeysManage, h.APIKeys.RevokeAPIKey},
+
+		{http.MethodGet, "/users", auth.PermUsersManage, h.Users.GetUsers},
+		{http.MethodGet, "/users/:id", auth.PermUsersManage, h.Users.GetUser},
+		{http.MethodPut, "/users/:id/role", auth.PermUsersManage, h.Users.SetUserRole},
+		{http.MethodGet, "/users/:id/role-changes", auth.PermUsersManage, h.Users.GetRoleChanges},
+		{http.MethodDelete, "/users/:id", auth.PermUsersManage, h.Users.DeleteUser},
+
+		{http.MethodGet, "/admin/external-cache", auth.PermCacheManage, h.Cache.GetExternalCache},
+		{http.MethodDelete, "/admin/external-cache", auth.PermCacheManage, h.Cache.FlushExternalCache},
+
+		{http.MethodGet, "/songs", auth.PermSongsRead, h.Songs.GetSongs},
+		{http.MethodGet, "/songs/search", auth.PermSongsRead, h.Songs.SearchSongs},
+		{http.MethodGet, "/songs/export", auth.PermSongsRead, h.Songs.ExportSongs},
+		{http.MethodGet, "/songs/:id", auth.PermSongsRead, h.Songs.GetSong},
+		{http.MethodGet, "/songs/:id/lyrics", auth.PermLyricsRead, h.Songs.GetLyrics},
+		{http.MethodGet, "/songs/:id/enrichment", auth.PermSongsRead, h.Songs.GetEnrichment},
+		{http.MethodPost, "/songs", auth.PermSongsWrite, h.Songs.AddSong},
+		{http.MethodPost, "/songs/import", auth.PermSongsWrite, h.Songs.ImportSongs},
+		{http.MethodPut, "/songs/:id", auth.PermSongsWrite, h.Songs.UpdateSong},
+		{http.MethodPatch, "/songs/:id", auth.PermSongsWrite, h.Songs.PatchSong},
+		{http.MethodDelete, "/songs/:id", auth.PermSongsDelete, h.Songs.DeleteSong},
+		{http.MethodGet, "/songs/:id/revisions", auth.PermSongsRead, h.Songs.GetRevisions},
+		{http.MethodGet, "/songs/:id/revisions/diff", auth.PermSongsRead, h.Songs.DiffRevisions},
+		{http.MethodGet, "/songs/:id/revisions/:rev", auth.PermSongsRead, h.Songs.GetRevision},
+		{http.MethodPost, "/songs/:id/revisions/:rev/revert", auth.PermSongsWrite, h.Songs.RevertSong},
+
+		{http.MethodGet, "/trash", auth.PermTrashManage, h.Songs.GetTrash},
+		{http.MethodPost, "/songs/:id/restore", auth.PermTrashManage, h.Songs.RestoreSong},
+		{http.MethodDelete, "/trash/:id", auth.PermTrashManage, h.Songs.PurgeSong},
+
+		{http.MethodGet, "/artists", auth.PermSongsRead, h.Artists.GetArtists},
+		{http.MethodGet, "/artists/:id", auth.PermSongsRead, h.Artists.GetArtist},
+		{http.MethodPost, "/artists", auth.PermSongsWrite, h.Artists.AddArtist},
+		{http.MethodPut, "/artists/:id", auth.PermSongsWrite, h.Artists.UpdateArtist},
+		{http.MethodDelete, "/artists/:id", auth.PermSongsDelete, h.Artists.DeleteArtist},
+
+		{http.MethodGet, "/albums", auth.PermSongsRead, h.Albums.GetAlbums},
+		{http.MethodGet, "/albums/:id", auth.PermSongsRead, h.Albums.GetAlbum},
+		{http.MethodPost, "/albums", auth.PermSongsWrite, h.Albums.AddAlbum},
+		{http.MethodPut, "/albums/:id", auth.PermSongsWrite, h.Albums.UpdateAlbum},
+		{http.MethodDelete, "/albums/:id", auth.PermSongsDelete, h.Albums.DeleteAlbum},
+
+		{http.MethodGet, "/playlists", auth.PermSongsRead, h.Playlists.GetPlaylists},
+		{http.MethodGet, "/playlists/:id", auth.PermSongsRead, h.Playlists.GetPlaylist},
+		{http.MethodPost, "/playlists", auth.PermPlaylistsWrite, h.Playlists.AddPlaylist},
+		{http.MethodPut, "/playlists/:id", auth.PermPlaylistsWrite, h.Playlists.UpdatePlaylist},
+		{http.MethodDelete, "/playlists/:id", auth.PermPlaylistsWrite, h.Playlists.DeletePlaylist},
+		{http.MethodPost, "/playlists/:id/entries", auth.PermPlaylistsWrite, h.Playlists.AddPlaylistEntry},
+		{http.MethodDelete, "/playlists/:id/entries/:entryId", auth.PermPlaylistsWrite, h.Playlists.RemovePlaylistEntry},
+		{http.MethodPost, "/playlists/:id/entries/:entryId/move", auth.PermPlaylistsWrite, h.Playlists.MovePlaylistEntry},
+	}
+}
+
+// SetupRouter регистрирует маршруты API после общих middleware, первым
+// из которых должен идти middleware.Authenticate. Каждый маршрут из routes
+// расходует квоту своей группы из limits и проверяет своё разрешение
+// через middleware.Authorize. /health и /metrics регистрируются раньше
+// общих middleware и доступны без аутентификации и квот.
+func SetupRouter(h Handlers, limits RateLimits, middlewares ...gin.HandlerFunc) *gin.Engine {
 	r := gin.Default()
+	r.GET("/health", h.Health.Health)
+	r.GET("/metrics", h.Health.Metrics)
+	r.Use(middlewares...)
+
+	authLimit := middleware.RateLimit("auth", limits.Auth)
+	r.POST("/auth/register", authLimit, h.Auth.Register)
+	r.POST("/auth/login", authLimit, h.Auth.Login)
+	r.POST("/auth/refresh", authLimit, h.Auth.Refresh)
+	r.GET("/auth/me", limits.limiter(route{method: http.MethodGet}), middleware.RequireUser(), h.Auth.Me)
 
-	r.GET("/songs", handlers.GetSongs)
-	r.GET("/songs/:id/lyrics", handlers.GetLyrics)
-	r.POST("/songs", handlers.AddSong)
-	r.PUT("/songs/:id", handlers.UpdateSong)
-	r.DELETE("/songs/:id", handlers.DeleteSong)
+	for _, rt := range routes(h) {
+		r.Handle(rt.method, rt.path, limits.limiter(rt), middleware.Authorize(rt.permission), rt.handler)
+	}
 
 	return r
 }
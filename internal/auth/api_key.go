@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Области доступа ключей API. ScopeAdmin включает все остальные.
+const (
+	ScopeSongsRead  = "songs:read"
+	ScopeSongsWrite = "songs:write"
+	ScopeLyricsRead = "lyrics:read"
+	ScopeAdmin      = "admin"
+)
+
+// Scopes — все известные области доступа
+var Scopes = []string{ScopeSongsRead, ScopeSongsWrite, ScopeLyricsRead, ScopeAdmin}
+
+// ValidScope сообщает, известна ли область доступа
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyPrefix отличает ключи API от JWT в заголовке Authorization
+const apiKeyPrefix = "ml_"
+
+// displayPrefixLength — сколько первых символов ключа хранится открыто,
+// чтобы владелец мог узнать ключ в списке
+const displayPrefixLength = len(apiKeyPrefix) + 8
+
+// IsAPIKey сообщает, похожа ли строка на ключ API, а не на JWT
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// GenerateAPIKey возвращает новый ключ, его открытый префикс и хэш для хранения
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + hex.EncodeToString(b)
+	return key, key[:displayPrefixLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey возвращает хэш ключа. У ключа 256 бит энтропии, поэтому
+// медленный хэш вроде bcrypt не нужен, а SHA-256 позволяет искать ключ по индексу.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
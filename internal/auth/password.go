@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinPasswordLength — минимальная длина пароля в байтах
+	MinPasswordLength = 8
+	// MaxPasswordLength — bcrypt учитывает только первые 72 байта пароля,
+	// более длинные пароли отклоняются, чтобы не обрезать их молча
+	MaxPasswordLength = 72
+)
+
+// ErrPasswordLength возвращается для пароля короче MinPasswordLength
+// или длиннее MaxPasswordLength
+var ErrPasswordLength = errors.New("password length out of range")
+
+// dummyHash сравнивается с паролем, когда пользователь не найден, чтобы
+// время ответа не выдавало существование учётной записи
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// HashPassword возвращает bcrypt-хэш пароля
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return "", ErrPasswordLength
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// CheckPassword сообщает, соответствует ли пароль хэшу.
+// Пустой hash проверяется против фиктивного хэша и всегда даёт false.
+// Пароль длиннее MaxPasswordLength не подходит ни к одному хэшу, иначе
+// bcrypt сравнил бы только его первые 72 байта.
+func CheckPassword(hash, password string) bool {
+	if hash == "" || len(password) > MaxPasswordLength {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
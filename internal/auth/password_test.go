@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashPasswordLength(t *testing.T) {
+	tests := []struct {
+		length int
+		ok     bool
+	}{
+		{7, false},
+		{8, true},
+		{72, true},
+		{73, false},
+	}
+	for _, tt := range tests {
+		password := strings.Repeat("p", tt.length)
+		hash, err := HashPassword(password)
+		if tt.ok != (err == nil) {
+			t.Errorf("HashPassword(%d bytes) = %v, want ok=%v", tt.length, err, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			if !errors.Is(err, ErrPasswordLength) {
+				t.Errorf("HashPassword(%d bytes) = %v, want ErrPasswordLength", tt.length, err)
+			}
+			continue
+		}
+		if !CheckPassword(hash, password) || CheckPassword(hash, password+"x") {
+			t.Errorf("CheckPassword mismatch for %d bytes", tt.length)
+		}
+	}
+
+	// Длина считается в байтах: 4 символа по 2 байта — это 8 байт
+	if _, err := HashPassword("ключ"); err != nil {
+		t.Errorf("8-byte password rejected: %v", err)
+	}
+}
+
+func TestCheckPasswordEmptyHash(t *testing.T) {
+	if CheckPassword("", "") || CheckPassword("", "dummy password") {
+		t.Error("empty hash matched a password")
+	}
+}
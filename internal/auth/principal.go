@@ -0,0 +1,47 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// principalKey — ключ gin.Context, под которым middleware сохраняет
+// аутентифицированного пользователя
+const principalKey = "auth.principal"
+
+// Principal — аутентифицированный пользователь запроса
+type Principal struct {
+	UserID   int    `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// APIKeyID — ключ API, которым выполнен запрос; 0 для входа по JWT
+	APIKeyID int `json:"apiKeyId,omitempty"`
+	// Scopes ограничивают запрос по ключу API; сессия пользователя
+	// по JWT ими не ограничена
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// HasScope сообщает, разрешена ли запросу область доступа scope
+func (p *Principal) HasScope(scope string) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPrincipal сохраняет пользователя в контексте запроса
+func SetPrincipal(c *gin.Context, p *Principal) {
+	c.Set(principalKey, p)
+}
+
+// PrincipalFrom возвращает пользователя запроса; false, если запрос анонимный
+func PrincipalFrom(c *gin.Context) (*Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*Principal)
+	return p, ok
+}
@@ -0,0 +1,122 @@
+package auth
+
+// Роли пользователей по возрастанию прав
+const (
+	RoleReader    = "reader"
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Roles — все роли по возрастанию прав
+var Roles = []string{RoleReader, RoleEditor, RoleModerator, RoleAdmin}
+
+// ValidRole сообщает, известна ли роль
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Permission — действие над библиотекой. Маршруты в api.SetupRouter
+// объявляют требуемое разрешение, роли получают разрешения из rolePermissions.
+type Permission string
+
+const (
+	PermSongsRead      Permission = "songs.read"
+	PermLyricsRead     Permission = "lyrics.read"
+	PermSongsWrite     Permission = "songs.write"
+	PermSongsDelete    Permission = "songs.delete"
+	PermTrashManage    Permission = "trash.manage"
+	PermPlaylistsWrite Permission = "playlists.write"
+	PermKeysManage     Permission = "keys.manage"
+	PermUsersManage    Permission = "users.manage"
+	PermCacheManage    Permission = "cache.manage"
+)
+
+// anonymousPermissions доступны без входа
+var anonymousPermissions = []Permission{PermSongsRead, PermLyricsRead}
+
+// rolePermissions — таблица прав ролей. Каждая роль перечисляет только то,
+// что добавляет к предыдущей: редактор изменяет, но не удаляет песни,
+// модератор удаляет и управляет корзиной, пользователями и кешем внешнего
+// API управляет только админ.
+var rolePermissions = map[string][]Permission{
+	RoleReader:    {PermSongsRead, PermLyricsRead, PermKeysManage},
+	RoleEditor:    {PermSongsWrite, PermPlaylistsWrite},
+	RoleModerator: {PermSongsDelete, PermTrashManage},
+	RoleAdmin:     {PermUsersManage, PermCacheManage},
+}
+
+// permissionScopes — область доступа, которая нужна ключу API для разрешения
+var permissionScopes = map[Permission]string{
+	PermSongsRead:      ScopeSongsRead,
+	PermLyricsRead:     ScopeLyricsRead,
+	PermSongsWrite:     ScopeSongsWrite,
+	PermSongsDelete:    ScopeSongsWrite,
+	PermTrashManage:    ScopeSongsWrite,
+	PermPlaylistsWrite: ScopeSongsWrite,
+	PermKeysManage:     ScopeAdmin,
+	PermUsersManage:    ScopeAdmin,
+	PermCacheManage:    ScopeAdmin,
+}
+
+// AnonymousAllows сообщает, доступно ли разрешение без входа
+func AnonymousAllows(perm Permission) bool {
+	for _, p := range anonymousPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleAllows сообщает, есть ли у роли разрешение с учётом прав младших ролей.
+// У неизвестной роли разрешений нет.
+func RoleAllows(role string, perm Permission) bool {
+	if !ValidRole(role) {
+		return false
+	}
+	for _, r := range Roles {
+		for _, p := range rolePermissions[r] {
+			if p == perm {
+				return true
+			}
+		}
+		if r == role {
+			return false
+		}
+	}
+	return false
+}
+
+// RolePermissions возвращает все разрешения роли
+func RolePermissions(role string) []Permission {
+	perms := []Permission{}
+	for _, r := range Roles {
+		perms = append(perms, rolePermissions[r]...)
+		if r == role {
+			return perms
+		}
+	}
+	return nil
+}
+
+// RoleAllowsScope сообщает, даёт ли область доступа ключа API роли хотя бы
+// одно разрешение. Область admin включает все остальные.
+func RoleAllowsScope(role, scope string) bool {
+	for perm, s := range permissionScopes {
+		if (s == scope || scope == ScopeAdmin) && RoleAllows(role, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeFor возвращает область доступа ключа API, нужную для разрешения
+func ScopeFor(perm Permission) string {
+	return permissionScopes[perm]
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Таблица прав — граница безопасности: любое изменение должно менять и тест
+func TestRoleAllows(t *testing.T) {
+	allPerms := []Permission{
+		PermSongsRead, PermLyricsRead, PermSongsWrite, PermSongsDelete, PermTrashManage,
+		PermPlaylistsWrite, PermKeysManage, PermUsersManage, PermCacheManage,
+	}
+	want := map[string][]Permission{
+		RoleReader:    {PermSongsRead, PermLyricsRead, PermKeysManage},
+		RoleEditor:    {PermSongsRead, PermLyricsRead, PermKeysManage, PermSongsWrite, PermPlaylistsWrite},
+		RoleModerator: {PermSongsRead, PermLyricsRead, PermKeysManage, PermSongsWrite, PermPlaylistsWrite, PermSongsDelete, PermTrashManage},
+		RoleAdmin:     allPerms,
+		"":            nil,
+		"root":        nil,
+	}
+	for role, perms := range want {
+		allowed := make(map[Permission]bool)
+		for _, p := range perms {
+			allowed[p] = true
+		}
+		for _, perm := range allPerms {
+			if got := RoleAllows(role, perm); got != allowed[perm] {
+				t.Errorf("RoleAllows(%q, %s) = %v, want %v", role, perm, got, allowed[perm])
+			}
+		}
+		if got := len(RolePermissions(role)); got != len(perms) {
+			t.Errorf("RolePermissions(%q) = %v, want %v", role, RolePermissions(role), perms)
+		}
+	}
+
+	if RoleAllows(RoleEditor, PermSongsDelete) {
+		t.Error("editor can delete songs")
+	}
+	for _, role := range Roles {
+		if RoleAllows(role, PermUsersManage) != (role == RoleAdmin) {
+			t.Errorf("users.manage for %s = %v", role, RoleAllows(role, PermUsersManage))
+		}
+	}
+}
+
+func TestAnonymousAllows(t *testing.T) {
+	for _, perm := range []Permission{PermSongsRead, PermLyricsRead} {
+		if !AnonymousAllows(perm) {
+			t.Errorf("anonymous %s denied", perm)
+		}
+	}
+	for _, perm := range []Permission{PermSongsWrite, PermSongsDelete, PermKeysManage, PermUsersManage} {
+		if AnonymousAllows(perm) {
+			t.Errorf("anonymous %s allowed", perm)
+		}
+	}
+}
+
+// Каждому разрешению соответствует известная область доступа ключа
+func TestScopeFor(t *testing.T) {
+	for perm := range permissionScopes {
+		if !ValidScope(ScopeFor(perm)) {
+			t.Errorf("ScopeFor(%s) = %q", perm, ScopeFor(perm))
+		}
+	}
+	if len(permissionScopes) != len(RolePermissions(RoleAdmin)) {
+		t.Errorf("permissions without scope: %v", RolePermissions(RoleAdmin))
+	}
+	got := RolePermissions(RoleReader)
+	if !reflect.DeepEqual(got, []Permission{PermSongsRead, PermLyricsRead, PermKeysManage}) {
+		t.Errorf("RolePermissions(reader) = %v", got)
+	}
+}
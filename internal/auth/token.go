@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"music-library/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Типы токенов: access передаётся в заголовке Authorization,
+// refresh обменивается на новую пару токенов
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+const issuer = "music-library"
+
+// ErrInvalidToken возвращается для поддельного, просроченного
+// или не того типа токена
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims — содержимое JWT. Subject — ID пользователя.
+type Claims struct {
+	Username string `json:"username"`
+	Type     string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// UserID возвращает ID пользователя из Subject
+func (c *Claims) UserID() int {
+	id, _ := strconv.Atoi(c.Subject)
+	return id
+}
+
+// TokenPair — ответ на регистрацию, вход и обновление токенов
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	// ExpiresIn — время жизни access-токена в секундах
+	ExpiresIn int `json:"expiresIn"`
+}
+
+// TokenService выпускает и проверяет JWT, подписанные HS256
+type TokenService struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenService(secret []byte, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{secret: secret, accessTTL: accessTTL, refreshTTL: refreshTTL}
+}
+
+// Issue выпускает пару access- и refresh-токенов для пользователя
+func (s *TokenService) Issue(user *models.User) (*TokenPair, error) {
+	access, err := s.sign(user, AccessToken, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := s.sign(user, RefreshToken, s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+func (s *TokenService) sign(user *models.User, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Username: user.Username,
+		Type:     tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.Itoa(user.ID),
+			ID:        randomID(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// Parse проверяет подпись, срок действия и тип токена
+func (s *TokenService) Parse(token, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims,
+		func(*jwt.Token) (interface{}, error) { return s.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Type != tokenType || claims.UserID() == 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// randomID возвращает случайный идентификатор токена (jti), чтобы
+// токены, выпущенные в одну секунду, различались
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"music-library/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestTokenTypes(t *testing.T) {
+	s := NewTokenService(testSecret, time.Minute, time.Hour)
+	pair, err := s.Issue(&models.User{ID: 7, Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := s.Parse(pair.AccessToken, AccessToken)
+	if err != nil || claims.UserID() != 7 || claims.Username != "alice" {
+		t.Fatalf("Parse(access) = %+v, %v", claims, err)
+	}
+	if _, err := s.Parse(pair.RefreshToken, RefreshToken); err != nil {
+		t.Fatalf("Parse(refresh): %v", err)
+	}
+
+	// Refresh-токен не заменяет access-токен и наоборот
+	if _, err := s.Parse(pair.RefreshToken, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("refresh token accepted as access token: %v", err)
+	}
+	if _, err := s.Parse(pair.AccessToken, RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token accepted as refresh token: %v", err)
+	}
+	if pair.ExpiresIn != 60 || pair.TokenType != "Bearer" {
+		t.Errorf("pair = %+v", pair)
+	}
+}
+
+func TestParseRejectsInvalidTokens(t *testing.T) {
+	s := NewTokenService(testSecret, time.Minute, time.Hour)
+	now := time.Now()
+	claims := func(mutate func(*Claims)) *Claims {
+		c := &Claims{
+			Username: "alice",
+			Type:     AccessToken,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Subject:   strconv.Itoa(7),
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}
+		mutate(c)
+		return c
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, c *Claims) string {
+		token, err := jwt.NewWithClaims(method, c).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := claims(func(*Claims) {})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong alg", sign(jwt.SigningMethodHS512, testSecret, valid)},
+		{"alg none", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid)},
+		{"wrong secret", sign(jwt.SigningMethodHS256, []byte("another secret of 32 bytes long!"), valid)},
+		{"wrong issuer", sign(jwt.SigningMethodHS256, testSecret, claims(func(c *Claims) { c.Issuer = "evil" }))},
+		{"expired", sign(jwt.SigningMethodHS256, testSecret, claims(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Second)) }))},
+		{"no expiry", sign(jwt.SigningMethodHS256, testSecret, claims(func(c *Claims) { c.ExpiresAt = nil }))},
+		{"subject 0", sign(jwt.SigningMethodHS256, testSecret, claims(func(c *Claims) { c.Subject = "0" }))},
+		{"subject not a number", sign(jwt.SigningMethodHS256, testSecret, claims(func(c *Claims) { c.Subject = "alice" }))},
+		{"garbage", "not.a.token"},
+	}
+	if _, err := s.Parse(sign(jwt.SigningMethodHS256, testSecret, valid), AccessToken); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Parse(tt.token, AccessToken); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Parse = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestIssuedTokenExpires(t *testing.T) {
+	s := NewTokenService(testSecret, -time.Second, time.Hour)
+	pair, err := s.Issue(&models.User{ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Parse(pair.AccessToken, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expired access token accepted: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package cache реализует LRU-кеш со сроком жизни записей
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Entry — запись кеша
+type Entry[K comparable, V any] struct {
+	Key       K
+	Value     V
+	ExpiresAt time.Time
+}
+
+// Stats — счётчики кеша с момента создания
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// LRU хранит не больше capacity записей, вытесняя давно не читавшиеся.
+// Просроченные записи не возвращаются и удаляются при обращении или
+// вытеснении. Безопасен для одновременного использования.
+type LRU[K comparable, V any] struct {
+	capacity int
+
+	mu    sync.Mutex
+	order *list.List // *Entry[K, V], в начале — недавно использованные
+	items map[K]*list.Element
+	stats Stats
+}
+
+// NewLRU создаёт кеш на capacity записей; capacity < 1 считается за 1
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+	}
+}
+
+// Get возвращает непросроченное значение по ключу
+func (c *LRU[K, V]) Get(key K, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if ok && !now.Before(el.Value.(*Entry[K, V]).ExpiresAt) {
+		c.remove(el)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(el)
+	return el.Value.(*Entry[K, V]).Value, true
+}
+
+// Set сохраняет значение до now+ttl, вытесняя при переполнении давно не
+// использованную запись
+func (c *LRU[K, V]) Set(key K, value V, ttl time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &Entry[K, V]{Key: key, Value: value, ExpiresAt: now.Add(ttl)}
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete удаляет запись и сообщает, была ли она в кеше
+func (c *LRU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if ok {
+		c.remove(el)
+	}
+	return ok
+}
+
+// Flush удаляет все записи и возвращает их число
+func (c *LRU[K, V]) Flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.order.Len()
+	c.order.Init()
+	clear(c.items)
+	return n
+}
+
+// Entries возвращает непросроченные записи от недавно использованных
+// к давним
+func (c *LRU[K, V]) Entries(now time.Time) []Entry[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]Entry[K, V], 0, c.order.Len())
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*Entry[K, V]); now.Before(e.ExpiresAt) {
+			entries = append(entries, *e)
+		}
+	}
+	return entries
+}
+
+// Len возвращает число записей вместе с ещё не удалёнными просроченными
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Capacity возвращает наибольшее число записей
+func (c *LRU[K, V]) Capacity() int {
+	return c.capacity
+}
+
+// Stats возвращает счётчики кеша
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *LRU[K, V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*Entry[K, V]).Key)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUGetSet(t *testing.T) {
+	c := NewLRU[string, int](2)
+	now := time.Now()
+
+	if _, ok := c.Get("a", now); ok {
+		t.Fatal("empty cache returned a value")
+	}
+	c.Set("a", 1, time.Minute, now)
+	c.Set("a", 2, time.Minute, now)
+	if v, ok := c.Get("a", now); !ok || v != 2 {
+		t.Fatalf("Get(a) = %d, %v; want 2", v, ok)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len = %d after overwriting a key", c.Len())
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	c := NewLRU[string, int](2)
+	now := time.Now()
+	c.Set("a", 1, time.Second, now)
+
+	if _, ok := c.Get("a", now.Add(999*time.Millisecond)); !ok {
+		t.Fatal("entry expired early")
+	}
+	if entries := c.Entries(now.Add(time.Second)); len(entries) != 0 {
+		t.Errorf("Entries returned expired entries: %+v", entries)
+	}
+	if _, ok := c.Get("a", now.Add(time.Second)); ok {
+		t.Fatal("expired entry returned")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expired entry was not removed on access, Len = %d", c.Len())
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2)
+	now := time.Now()
+	c.Set("a", 1, time.Minute, now)
+	c.Set("b", 2, time.Minute, now)
+	// Чтение делает a недавно использованной, вытесняется b
+	c.Get("a", now)
+	c.Set("c", 3, time.Minute, now)
+
+	if _, ok := c.Get("b", now); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.Get(k, now); !ok {
+			t.Errorf("entry %s was evicted", k)
+		}
+	}
+	if s := c.Stats(); s.Evictions != 1 {
+		t.Errorf("evictions = %d, want 1", s.Evictions)
+	}
+
+	entries := c.Entries(now)
+	if len(entries) != 2 || entries[0].Key != "c" || entries[1].Key != "a" {
+		t.Errorf("entries = %+v, want c then a", entries)
+	}
+}
+
+func TestLRUDeleteAndFlush(t *testing.T) {
+	c := NewLRU[string, int](0)
+	if c.Capacity() != 1 {
+		t.Errorf("capacity = %d, want 1", c.Capacity())
+	}
+
+	c = NewLRU[string, int](3)
+	now := time.Now()
+	for i, k := range []string{"a", "b", "c"} {
+		c.Set(k, i, time.Minute, now)
+	}
+	if !c.Delete("a") || c.Delete("a") {
+		t.Error("Delete should report whether the key was present")
+	}
+	if n := c.Flush(); n != 2 || c.Len() != 0 {
+		t.Errorf("Flush = %d, Len = %d", n, c.Len())
+	}
+	if _, ok := c.Get("b", now); ok {
+		t.Error("flushed entry returned")
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"music-library/internal/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// String возвращает значение переменной окружения или def, если она не задана
+func String(name, def string) string {
+	if v, ok := os.LookupEnv(name); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Int разбирает целочисленную переменную окружения.
+// Некорректное значение логируется и заменяется на def.
+func Int(name string, def int) int {
+	v := String(name, "")
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		invalid(name, v, def)
+		return def
+	}
+	return n
+}
+
+// Bool разбирает логическую переменную окружения (true, false, 1, 0 и т.п.)
+func Bool(name string, def bool) bool {
+	v := String(name, "")
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		invalid(name, v, def)
+		return def
+	}
+	return b
+}
+
+// Duration разбирает переменную окружения в формате time.ParseDuration, например "1h30m"
+func Duration(name string, def time.Duration) time.Duration {
+	v := String(name, "")
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		invalid(name, v, def)
+		return def
+	}
+	return d
+}
+
+func invalid(name, value string, def interface{}) {
+	logger.Log.WithFields(logrus.Fields{"name": name, "value": value, "default": def}).
+		Warn("Invalid environment variable, using default")
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AlbumHandler обслуживает эндпоинты /albums
+type AlbumHandler struct {
+	repo    repository.AlbumRepository
+	artists repository.ArtistRepository
+	songs   repository.SongRepository
+}
+
+func NewAlbumHandler(repo repository.AlbumRepository, artists repository.ArtistRepository, songs repository.SongRepository) *AlbumHandler {
+	return &AlbumHandler{repo: repo, artists: artists, songs: songs}
+}
+
+// albumInput — тело запросов на создание и изменение альбома.
+// Исполнитель задаётся через artistId или по имени в artist.
+type albumInput struct {
+	ArtistID    int    `json:"artistId"`
+	Artist      string `json:"artist"`
+	Title       string `json:"title" binding:"required"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+// GetAlbums godoc
+// @Summary      Получение списка альбомов
+// @Description  Возвращает альбомы с фильтрацией по исполнителю и пагинацией
+// @Tags         Albums
+// @Param        artist_id  query   int  false  "ID исполнителя"
+// @Param        page       query   int  false  "Номер страницы" default(1)
+// @Param        limit      query   int  false  "Количество элементов на странице" default(10)
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  map[string]string
+// @Failure      429        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /albums [get]
+func (h *AlbumHandler) GetAlbums(c *gin.Context) {
+	logger.Log.Debug("Entering GetAlbums handler")
+
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
+
+	filter := repository.AlbumFilter{Limit: limit, Offset: (page - 1) * limit}
+	if artistStr := c.Query("artist_id"); artistStr != "" {
+		artistID, err := strconv.Atoi(artistStr)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{"artist_id": artistStr}).Debug("Invalid artist_id parameter")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid artist_id parameter"})
+			return
+		}
+		filter.ArtistID = artistID
+	}
+
+	albums, err := h.repo.List(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching albums from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching albums"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"albums": albums, "page": page, "limit": limit})
+	logger.Log.Info("Albums fetched successfully")
+}
+
+// GetAlbum godoc
+// @Summary      Получение альбома с треклистом
+// @Description  Возвращает альбом и его песни, упорядоченные по номеру трека
+// @Tags         Albums
+// @Param        id   path      int  true  "ID альбома"
+// @Success      200  {object}  models.Album
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /albums/{id} [get]
+func (h *AlbumHandler) GetAlbum(c *gin.Context) {
+	logger.Log.Debug("Entering GetAlbum handler")
+
+	id, ok := parseID(c, "album")
+	if !ok {
+		return
+	}
+
+	album, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, id, "Error fetching album")
+		return
+	}
+
+	album.Tracks, err = h.songs.List(c.Request.Context(), repository.SongFilter{AlbumID: id})
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching album tracks from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching album"})
+		return
+	}
+
+	c.JSON(http.StatusOK, album)
+}
+
+// AddAlbum godoc
+// @Summary      Добавление альбома
+// @Description  Создаёт альбом. Если исполнитель указан по имени, он будет найден или создан.
+// @Tags         Albums
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        album  body      albumInput  true  "Данные альбома"
+// @Success      201    {object}  models.Album
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      409    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /albums [post]
+func (h *AlbumHandler) AddAlbum(c *gin.Context) {
+	album, ok := h.bindAlbum(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), album); err != nil {
+		h.respondError(c, err, 0, "Failed to save album")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"album_id": album.ID}).Info("Album added successfully")
+	h.respondAlbum(c, http.StatusCreated, album.ID)
+}
+
+// UpdateAlbum godoc
+// @Summary      Изменение альбома
+// @Tags         Albums
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id     path      int         true  "ID альбома"
+// @Param        album  body      albumInput  true  "Новые данные альбома"
+// @Success      200    {object}  models.Album
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      409    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /albums/{id} [put]
+func (h *AlbumHandler) UpdateAlbum(c *gin.Context) {
+	logger.Log.Debug("Entering UpdateAlbum handler")
+
+	id, ok := parseID(c, "album")
+	if !ok {
+		return
+	}
+
+	album, ok := h.bindAlbum(c)
+	if !ok {
+		return
+	}
+
+	album.ID = id
+	if err := h.repo.Update(c.Request.Context(), album); err != nil {
+		h.respondError(c, err, id, "Failed to update album")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"album_id": id}).Info("Album updated successfully")
+	h.respondAlbum(c, http.StatusOK, id)
+}
+
+// DeleteAlbum godoc
+// @Summary      Удаление альбома
+// @Description  Удаляет альбом. Песни альбома остаются в библиотеке без привязки к альбому.
+// @Tags         Albums
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID альбома"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /albums/{id} [delete]
+func (h *AlbumHandler) DeleteAlbum(c *gin.Context) {
+	logger.Log.Debug("Entering DeleteAlbum handler")
+
+	id, ok := parseID(c, "album")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Failed to delete album")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"album_id": id}).Info("Album deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Album deleted successfully"})
+}
+
+// bindAlbum разбирает albumInput и определяет исполнителя.
+// При ошибке отвечает клиенту и возвращает false.
+func (h *AlbumHandler) bindAlbum(c *gin.Context) (*models.Album, bool) {
+	var input albumInput
+	if err := c.ShouldBindJSON(&input); err != nil || models.CleanName(input.Title) == "" {
+		logger.Log.WithError(err).Debug("Invalid input data for album")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return nil, false
+	}
+
+	album := &models.Album{ArtistID: input.ArtistID, Title: input.Title, ReleaseDate: input.ReleaseDate}
+	switch {
+	case input.ArtistID != 0:
+		if _, err := h.artists.Get(c.Request.Context(), input.ArtistID); err != nil {
+			h.respondError(c, err, 0, "Failed to resolve artist")
+			return nil, false
+		}
+	case models.CleanName(input.Artist) != "":
+		artist, err := h.artists.Resolve(c.Request.Context(), input.Artist)
+		if err != nil {
+			h.respondError(c, err, 0, "Failed to resolve artist")
+			return nil, false
+		}
+		album.ArtistID = artist.ID
+	default:
+		logger.Log.Debug("Album artist is not specified")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Artist is required"})
+		return nil, false
+	}
+	return album, true
+}
+
+// respondAlbum отвечает альбомом в том виде, в каком он сохранён
+func (h *AlbumHandler) respondAlbum(c *gin.Context, status, id int) {
+	album, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, id, "Error fetching album")
+		return
+	}
+	c.JSON(status, album)
+}
+
+// respondError переводит ошибки AlbumRepository в HTTP-ответы.
+// Для неизвестных ошибок отвечает 500 с сообщением fallback.
+func (h *AlbumHandler) respondError(c *gin.Context, err error, id int, fallback string) {
+	fields := logrus.Fields{"album_id": id}
+	switch {
+	case errors.Is(err, repository.ErrAlbumNotFound):
+		logger.Log.WithFields(fields).Debug("Album not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album not found"})
+	case errors.Is(err, repository.ErrAlbumExists):
+		logger.Log.WithFields(fields).Debug("Album with the same title already exists")
+		c.JSON(http.StatusConflict, gin.H{"error": "Album already exists"})
+	case errors.Is(err, repository.ErrArtistNotFound):
+		logger.Log.WithFields(fields).Debug("Album artist not found in database")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Artist not found"})
+	default:
+		logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"music-library/internal/auth"
+	"music-library/internal/logger"
+	"music-library/internal/middleware"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// APIKeyHandler обслуживает эндпоинты /api-keys. Пользователь управляет
+// только своими ключами.
+type APIKeyHandler struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyHandler(repo repository.APIKeyRepository) *APIKeyHandler {
+	return &APIKeyHandler{repo: repo}
+}
+
+// apiKeyInput — тело запроса на создание ключа
+type apiKeyInput struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresAt — необязательный срок действия ключа в RFC 3339
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// apiKeyResponse — ключ вместе с секретом; секрет показывается только
+// при создании и ротации
+type apiKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// GetAPIKeys godoc
+// @Summary      Список ключей API
+// @Description  Возвращает ключи текущего пользователя, включая отозванные. Секреты ключей не возвращаются.
+// @Tags         API keys
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Success      200  {array}   models.APIKey
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	logger.Log.Debug("Entering GetAPIKeys handler")
+
+	principal, _ := auth.PrincipalFrom(c)
+	keys, err := h.repo.List(c.Request.Context(), principal.UserID)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching API keys from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// AddAPIKey godoc
+// @Summary      Создание ключа API
+// @Description  Создаёт ключ с областями доступа songs:read, songs:write, lyrics:read и admin. Секрет возвращается один раз.
+// @Description  Ключ передаётся в заголовке Authorization: Bearer или X-API-Key. Область, которая не даёт роли владельца
+// @Description  ни одного разрешения, отклоняется с 403.
+// @Tags         API keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        key  body      apiKeyInput  true  "Название, области доступа и срок действия"
+// @Success      201  {object}  apiKeyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys [post]
+func (h *APIKeyHandler) AddAPIKey(c *gin.Context) {
+	logger.Log.Debug("Entering AddAPIKey handler")
+
+	var input apiKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Name) == "" {
+		logger.Log.WithError(err).Debug("Invalid input data for API key")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	scopes, ok := normalizeScopes(input.Scopes)
+	if !ok {
+		logger.Log.WithFields(logrus.Fields{"scopes": input.Scopes}).Debug("Invalid API key scopes")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scopes", "allowed": auth.Scopes})
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		logger.Log.Debug("API key expiration is in the past")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expiresAt"})
+		return
+	}
+
+	// Ключ, созданный другим ключом, не может получить больше прав, чем у того,
+	// а область, бесполезная для роли владельца, скорее всего ошибка
+	principal, _ := auth.PrincipalFrom(c)
+	for _, scope := range scopes {
+		switch {
+		case !principal.HasScope(scope):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":  "API key lacks required scope",
+				"reason": middleware.ReasonInsufficientScope,
+				"scope":  scope,
+			})
+			return
+		case !auth.RoleAllowsScope(principal.Role, scope):
+			logger.Log.WithFields(logrus.Fields{"user_id": principal.UserID, "role": principal.Role, "scope": scope}).Debug("API key scope not allowed for role")
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":  "Role does not allow scope",
+				"reason": middleware.ReasonInsufficientRole,
+				"scope":  scope,
+				"role":   principal.Role,
+			})
+			return
+		}
+	}
+
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to generate API key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	key := &models.APIKey{
+		UserID:    principal.UserID,
+		Username:  principal.Username,
+		Name:      strings.TrimSpace(input.Name),
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := h.repo.Create(c.Request.Context(), key); err != nil {
+		logger.Log.WithError(err).Debug("Failed to save API key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"api_key_id": key.ID, "user_id": key.UserID, "scopes": scopes}).Info("API key created")
+	c.JSON(http.StatusCreated, apiKeyResponse{APIKey: *key, Key: secret})
+}
+
+// RotateAPIKey godoc
+// @Summary      Ротация ключа API
+// @Description  Выдаёт ключу новый секрет с теми же областями доступа. Старый секрет перестаёт действовать сразу.
+// @Tags         API keys
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID ключа"
+// @Success      200  {object}  apiKeyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	logger.Log.Debug("Entering RotateAPIKey handler")
+
+	id, ok := parseID(c, "API key")
+	if !ok {
+		return
+	}
+
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to generate API key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key"})
+		return
+	}
+
+	principal, _ := auth.PrincipalFrom(c)
+	key, err := h.repo.Rotate(c.Request.Context(), principal.UserID, id, prefix, hash)
+	if err != nil {
+		h.respondError(c, err, id, "Failed to rotate API key")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"api_key_id": id, "user_id": principal.UserID}).Info("API key rotated")
+	c.JSON(http.StatusOK, apiKeyResponse{APIKey: *key, Key: secret})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Отзыв ключа API
+// @Description  Отзывает ключ; он остаётся в списке с датой отзыва
+// @Tags         API keys
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID ключа"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	logger.Log.Debug("Entering RevokeAPIKey handler")
+
+	id, ok := parseID(c, "API key")
+	if !ok {
+		return
+	}
+
+	principal, _ := auth.PrincipalFrom(c)
+	if err := h.repo.Revoke(c.Request.Context(), principal.UserID, id); err != nil {
+		h.respondError(c, err, id, "Failed to revoke API key")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"api_key_id": id, "user_id": principal.UserID}).Info("API key revoked")
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// normalizeScopes проверяет области доступа и убирает повторы.
+// Пустой список недопустим.
+func normalizeScopes(scopes []string) ([]string, bool) {
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !auth.ValidScope(s) {
+			return nil, false
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out, len(out) > 0
+}
+
+// respondError переводит ошибки APIKeyRepository в HTTP-ответы.
+// Для неизвестных ошибок отвечает 500 с сообщением fallback.
+func (h *APIKeyHandler) respondError(c *gin.Context, err error, id int, fallback string) {
+	fields := logrus.Fields{"api_key_id": id}
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		logger.Log.WithFields(fields).Debug("API key not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"music-library/internal/auth"
+	"music-library/internal/middleware"
+)
+
+// addKey создаёт ключ API от имени владельца token
+func (a *authAPI) addKey(token string, scopes ...string) apiKeyResponse {
+	a.t.Helper()
+
+	w := a.do(http.MethodPost, "/api-keys", map[string]interface{}{"name": "ci", "scopes": scopes}, bearer(token)...)
+	if w.Code != http.StatusCreated {
+		a.t.Fatalf("POST /api-keys: status %d, body %s", w.Code, w.Body)
+	}
+	var key apiKeyResponse
+	decode(a.t, w, &key)
+	return key
+}
+
+func keyPath(id int) string {
+	return "/api-keys/" + strconv.Itoa(id)
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	api := newAuthAPI(t)
+	api.user("root", auth.RoleAdmin)
+	_, token := api.user("alice", auth.RoleEditor)
+
+	key := api.addKey(token, "songs:read", "SONGS:WRITE", "songs:read")
+	if len(key.Scopes) != 2 || key.Key == "" || key.Prefix != key.Key[:len(key.Prefix)] {
+		t.Fatalf("created key = %+v", key)
+	}
+
+	// Ключ хранится только в виде хэша, список не раскрывает секрет
+	w := api.do(http.MethodGet, "/api-keys", nil, bearer(token)...)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: status %d", w.Code)
+	}
+	var keys []apiKeyResponse
+	decode(t, w, &keys)
+	if len(keys) != 1 || keys[0].Key != "" || keys[0].Hash != "" {
+		t.Errorf("listed keys = %+v", keys)
+	}
+
+	// Ключ аутентифицирует запросы и в X-API-Key, и в Authorization
+	song := map[string]string{"group": "Muse", "song": "Uprising"}
+	if w := api.do(http.MethodPost, "/songs", song, "X-API-Key", key.Key); w.Code != http.StatusAccepted {
+		t.Fatalf("write with key: status %d, body %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, bearer(key.Key)...); w.Code != http.StatusOK {
+		t.Fatalf("read with key: status %d", w.Code)
+	}
+
+	// Время последнего использования записывается при аутентификации
+	w = api.do(http.MethodGet, "/api-keys", nil, bearer(token)...)
+	decode(t, w, &keys)
+	if keys[0].LastUsedAt == nil {
+		t.Error("last used time was not recorded")
+	}
+
+	// После ротации старый секрет не действует, новый — действует
+	w = api.do(http.MethodPost, keyPath(key.ID)+"/rotate", nil, bearer(token)...)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rotate: status %d, body %s", w.Code, w.Body)
+	}
+	var rotated apiKeyResponse
+	decode(t, w, &rotated)
+	if rotated.Key == key.Key || len(rotated.Scopes) != 2 {
+		t.Errorf("rotated key = %+v", rotated)
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, bearer(key.Key)...); w.Code != http.StatusUnauthorized {
+		t.Errorf("rotated-away key: status %d, want 401", w.Code)
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, bearer(rotated.Key)...); w.Code != http.StatusOK {
+		t.Errorf("rotated key: status %d", w.Code)
+	}
+
+	// Отозванный ключ не действует, повторный отзыв и ротация — 404
+	if w := api.do(http.MethodDelete, keyPath(key.ID), nil, bearer(token)...); w.Code != http.StatusOK {
+		t.Fatalf("revoke: status %d", w.Code)
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, bearer(rotated.Key)...); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status %d, want 401", w.Code)
+	}
+	if w := api.do(http.MethodDelete, keyPath(key.ID), nil, bearer(token)...); w.Code != http.StatusNotFound {
+		t.Errorf("revoke again: status %d, want 404", w.Code)
+	}
+	if w := api.do(http.MethodPost, keyPath(key.ID)+"/rotate", nil, bearer(token)...); w.Code != http.StatusNotFound {
+		t.Errorf("rotate revoked key: status %d, want 404", w.Code)
+	}
+}
+
+// Чужие ключи неотличимы от несуществующих
+func TestAPIKeysOfAnotherUser(t *testing.T) {
+	api := newAuthAPI(t)
+	api.user("root", auth.RoleAdmin)
+	_, alice := api.user("alice", auth.RoleEditor)
+	_, bob := api.user("bob", auth.RoleEditor)
+	key := api.addKey(alice, "songs:read")
+
+	if w := api.do(http.MethodPost, keyPath(key.ID)+"/rotate", nil, bearer(bob)...); w.Code != http.StatusNotFound {
+		t.Errorf("rotate another user's key: status %d, want 404", w.Code)
+	}
+	if w := api.do(http.MethodDelete, keyPath(key.ID), nil, bearer(bob)...); w.Code != http.StatusNotFound {
+		t.Errorf("revoke another user's key: status %d, want 404", w.Code)
+	}
+	var keys []apiKeyResponse
+	decode(t, api.do(http.MethodGet, "/api-keys", nil, bearer(bob)...), &keys)
+	if len(keys) != 0 {
+		t.Errorf("bob sees alice's keys: %+v", keys)
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, bearer(key.Key)...); w.Code != http.StatusOK {
+		t.Errorf("alice's key stopped working: status %d", w.Code)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	api := newAuthAPI(t)
+	api.user("root", auth.RoleAdmin)
+	_, token := api.user("alice", auth.RoleEditor)
+	readOnly := api.addKey(token, "songs:read")
+
+	w := api.do(http.MethodPost, "/songs", map[string]string{"group": "Muse", "song": "Uprising"}, bearer(readOnly.Key)...)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("write with read-only key: status %d, want 403", w.Code)
+	}
+	var denied map[string]string
+	decode(t, w, &denied)
+	if denied["reason"] != middleware.ReasonInsufficientScope || denied["scope"] != auth.ScopeSongsWrite {
+		t.Errorf("403 body = %v", denied)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		scopes []string
+		status int
+	}{
+		{"unknown scope", []string{"songs:everything"}, http.StatusBadRequest},
+		{"no scopes", []string{}, http.StatusBadRequest},
+	} {
+		w := api.do(http.MethodPost, "/api-keys", map[string]interface{}{"name": "x", "scopes": tt.scopes}, bearer(token)...)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
+
+// Управлять ключами может только ключ с областью admin, и выпущенный им
+// ключ не шире его самого
+func TestAPIKeyMintedByKey(t *testing.T) {
+	api := newAuthAPI(t)
+	api.user("root", auth.RoleAdmin)
+	_, token := api.user("alice", auth.RoleEditor)
+
+	admin := api.addKey(token, "admin")
+	child := api.addKey(admin.Key, "songs:read", "songs:write")
+	if len(child.Scopes) != 2 {
+		t.Errorf("child scopes = %v", child.Scopes)
+	}
+
+	narrow := api.addKey(token, "songs:read", "songs:write", "lyrics:read")
+	w := api.do(http.MethodPost, "/api-keys", map[string]interface{}{"name": "x", "scopes": []string{"songs:read"}}, bearer(narrow.Key)...)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("key without admin scope minted a key: status %d", w.Code)
+	}
+	var denied map[string]string
+	decode(t, w, &denied)
+	if denied["reason"] != middleware.ReasonInsufficientScope || denied["scope"] != auth.ScopeAdmin {
+		t.Errorf("403 body = %v", denied)
+	}
+}
+
+// Область, которая не даёт роли владельца ни одного разрешения, отклоняется
+func TestAPIKeyScopeBeyondRole(t *testing.T) {
+	api := newAuthAPI(t)
+	api.user("root", auth.RoleAdmin)
+	_, reader := api.user("reader", auth.RoleReader)
+
+	w := api.do(http.MethodPost, "/api-keys", map[string]interface{}{"name": "x", "scopes": []string{"songs:write"}}, bearer(reader)...)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("reader minted a songs:write key: status %d", w.Code)
+	}
+	var denied map[string]string
+	decode(t, w, &denied)
+	if denied["reason"] != middleware.ReasonInsufficientRole || denied["scope"] != auth.ScopeSongsWrite {
+		t.Errorf("403 body = %v", denied)
+	}
+
+	key := api.addKey(reader, "songs:read", "lyrics:read")
+	if len(key.Scopes) != 2 {
+		t.Errorf("reader key scopes = %v", key.Scopes)
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ArtistHandler обслуживает эндпоинты /artists
+type ArtistHandler struct {
+	repo   repository.ArtistRepository
+	songs  repository.SongRepository
+	albums repository.AlbumRepository
+}
+
+func NewArtistHandler(repo repository.ArtistRepository, songs repository.SongRepository, albums repository.AlbumRepository) *ArtistHandler {
+	return &ArtistHandler{repo: repo, songs: songs, albums: albums}
+}
+
+// artistInput — тело запросов на создание и изменение исполнителя
+type artistInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// GetArtists godoc
+// @Summary      Получение списка исполнителей
+// @Description  Возвращает исполнителей в алфавитном порядке с пагинацией
+// @Tags         Artists
+// @Param        page   query   int  false  "Номер страницы" default(1)
+// @Param        limit  query   int  false  "Количество элементов на странице" default(10)
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /artists [get]
+func (h *ArtistHandler) GetArtists(c *gin.Context) {
+	logger.Log.Debug("Entering GetArtists handler")
+
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
+
+	artists, err := h.repo.List(c.Request.Context(), limit, (page-1)*limit)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching artists from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching artists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"artists": artists, "page": page, "limit": limit})
+	logger.Log.Info("Artists fetched successfully")
+}
+
+// GetArtist godoc
+// @Summary      Получение исполнителя
+// @Tags         Artists
+// @Param        id   path      int  true  "ID исполнителя"
+// @Success      200  {object}  models.Artist
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /artists/{id} [get]
+func (h *ArtistHandler) GetArtist(c *gin.Context) {
+	logger.Log.Debug("Entering GetArtist handler")
+
+	id, ok := parseID(c, "artist")
+	if !ok {
+		return
+	}
+
+	artist, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, id, "Error fetching artist")
+		return
+	}
+
+	c.JSON(http.StatusOK, artist)
+}
+
+// AddArtist godoc
+// @Summary      Добавление исполнителя
+// @Description  Создаёт исполнителя. Имена сравниваются без учёта регистра и лишних пробелов.
+// @Tags         Artists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        artist  body      artistInput  true  "Данные исполнителя"
+// @Success      201     {object}  models.Artist
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      409     {object}  map[string]string
+// @Failure      429     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /artists [post]
+func (h *ArtistHandler) AddArtist(c *gin.Context) {
+	var input artistInput
+	if err := c.ShouldBindJSON(&input); err != nil || models.CleanName(input.Name) == "" {
+		logger.Log.WithError(err).Debug("Invalid input data for adding an artist")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	artist := models.Artist{Name: input.Name}
+	if err := h.repo.Create(c.Request.Context(), &artist); err != nil {
+		h.respondError(c, err, 0, "Failed to save artist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"artist_id": artist.ID}).Info("Artist added successfully")
+	c.JSON(http.StatusCreated, artist)
+}
+
+// UpdateArtist godoc
+// @Summary      Переименование исполнителя
+// @Tags         Artists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id      path      int          true  "ID исполнителя"
+// @Param        artist  body      artistInput  true  "Новые данные исполнителя"
+// @Success      200     {object}  models.Artist
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      409     {object}  map[string]string
+// @Failure      429     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /artists/{id} [put]
+func (h *ArtistHandler) UpdateArtist(c *gin.Context) {
+	logger.Log.Debug("Entering UpdateArtist handler")
+
+	id, ok := parseID(c, "artist")
+	if !ok {
+		return
+	}
+
+	var input artistInput
+	if err := c.ShouldBindJSON(&input); err != nil || models.CleanName(input.Name) == "" {
+		logger.Log.WithError(err).Debug("Invalid input for updating artist")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	artist := models.Artist{ID: id, Name: input.Name}
+	if err := h.repo.Update(c.Request.Context(), &artist); err != nil {
+		h.respondError(c, err, id, "Failed to update artist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"artist_id": id}).Info("Artist updated successfully")
+	c.JSON(http.StatusOK, artist)
+}
+
+// DeleteArtist godoc
+// @Summary      Удаление исполнителя
+// @Description  Удаляет исполнителя, если у него нет песен и альбомов
+// @Tags         Artists
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID исполнителя"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /artists/{id} [delete]
+func (h *ArtistHandler) DeleteArtist(c *gin.Context) {
+	logger.Log.Debug("Entering DeleteArtist handler")
+
+	id, ok := parseID(c, "artist")
+	if !ok {
+		return
+	}
+
+	// Песни из корзины тоже ссылаются на исполнителя
+	songs, err := h.songs.List(c.Request.Context(), repository.SongFilter{ArtistID: id, Trash: repository.WithTrashed, Limit: 1})
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching artist songs from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete artist"})
+		return
+	}
+	albums, err := h.albums.List(c.Request.Context(), repository.AlbumFilter{ArtistID: id, Limit: 1})
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching artist albums from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete artist"})
+		return
+	}
+	if len(songs) > 0 || len(albums) > 0 {
+		h.respondError(c, repository.ErrArtistInUse, id, "")
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Failed to delete artist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"artist_id": id}).Info("Artist deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Artist deleted successfully"})
+}
+
+// respondError переводит ошибки ArtistRepository в HTTP-ответы.
+// Для неизвестных ошибок отвечает 500 с сообщением fallback.
+func (h *ArtistHandler) respondError(c *gin.Context, err error, id int, fallback string) {
+	fields := logrus.Fields{"artist_id": id}
+	switch {
+	case errors.Is(err, repository.ErrArtistNotFound):
+		logger.Log.WithFields(fields).Debug("Artist not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artist not found"})
+	case errors.Is(err, repository.ErrArtistExists):
+		logger.Log.WithFields(fields).Debug("Artist with the same name already exists")
+		c.JSON(http.StatusConflict, gin.H{"error": "Artist already exists"})
+	case errors.Is(err, repository.ErrArtistInUse):
+		logger.Log.WithFields(fields).Debug("Artist still has songs or albums")
+		c.JSON(http.StatusConflict, gin.H{"error": "Artist has songs or albums"})
+	default:
+		logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
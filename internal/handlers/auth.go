@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"music-library/internal/auth"
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// usernamePattern — допустимые имена пользователей
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,50}$`)
+
+// AuthHandler обслуживает регистрацию, вход и обновление токенов
+type AuthHandler struct {
+	users  repository.UserRepository
+	tokens *auth.TokenService
+}
+
+func NewAuthHandler(users repository.UserRepository, tokens *auth.TokenService) *AuthHandler {
+	return &AuthHandler{users: users, tokens: tokens}
+}
+
+// credentialsInput — тело запросов регистрации и входа
+type credentialsInput struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// refreshInput — тело запроса обновления токенов
+type refreshInput struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// authResponse — пользователь и выпущенные для него токены
+type authResponse struct {
+	User models.User `json:"user"`
+	auth.TokenPair
+}
+
+// meResponse — текущий пользователь и разрешения его роли
+type meResponse struct {
+	models.User
+	Permissions []auth.Permission `json:"permissions"`
+}
+
+// Register godoc
+// @Summary      Регистрация
+// @Description  Создаёт пользователя и выдаёт пару токенов. Имя — от 3 до 50 латинских букв, цифр и символов _.-, без учёта регистра;
+// @Description  пароль — от 8 до 72 байт. Новый пользователь получает роль reader, самый первый — admin.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      credentialsInput  true  "Имя пользователя и пароль"
+// @Success      201          {object}  authResponse
+// @Failure      400          {object}  map[string]string
+// @Failure      409          {object}  map[string]string
+// @Failure      429          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	logger.Log.Debug("Entering Register handler")
+
+	var input credentialsInput
+	if err := c.ShouldBindJSON(&input); err != nil || !usernamePattern.MatchString(strings.TrimSpace(input.Username)) {
+		logger.Log.WithError(err).Debug("Invalid input data for registration")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username"})
+		return
+	}
+
+	hash, err := auth.HashPassword(input.Password)
+	if errors.Is(err, auth.ErrPasswordLength) {
+		logger.Log.Debug("Password length out of range")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be 8 to 72 bytes long"})
+		return
+	}
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to hash password")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		return
+	}
+
+	user := &models.User{Username: strings.TrimSpace(input.Username), PasswordHash: hash, Role: auth.RoleReader}
+	if err := h.users.Create(c.Request.Context(), user); err != nil {
+		if errors.Is(err, repository.ErrUserExists) {
+			logger.Log.WithFields(logrus.Fields{"username": user.Username}).Debug("Username already taken")
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already taken"})
+			return
+		}
+		logger.Log.WithError(err).Debug("Failed to save user")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"user_id": user.ID}).Info("User registered successfully")
+	h.respondTokens(c, http.StatusCreated, user)
+}
+
+// Login godoc
+// @Summary      Вход
+// @Description  Проверяет имя пользователя и пароль и выдаёт пару токенов
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      credentialsInput  true  "Имя пользователя и пароль"
+// @Success      200          {object}  authResponse
+// @Failure      400          {object}  map[string]string
+// @Failure      401          {object}  map[string]string
+// @Failure      429          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	logger.Log.Debug("Entering Login handler")
+
+	var input credentialsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Log.WithError(err).Debug("Invalid input data for login")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	user, err := h.users.GetByUsername(c.Request.Context(), input.Username)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		logger.Log.WithError(err).Debug("Error fetching user from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	// Пароль проверяется и для несуществующего пользователя, чтобы время
+	// ответа не выдавало, занято ли имя
+	hash := ""
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	if !auth.CheckPassword(hash, input.Password) {
+		logger.Log.WithFields(logrus.Fields{"username": input.Username}).Info("Failed login attempt")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"user_id": user.ID}).Info("User logged in successfully")
+	h.respondTokens(c, http.StatusOK, user)
+}
+
+// Refresh godoc
+// @Summary      Обновление токенов
+// @Description  Обменивает refresh-токен на новую пару токенов
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      refreshInput  true  "Refresh-токен"
+// @Success      200    {object}  authResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	logger.Log.Debug("Entering Refresh handler")
+
+	var input refreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Log.WithError(err).Debug("Invalid input data for token refresh")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	claims, err := h.tokens.Parse(input.RefreshToken, auth.RefreshToken)
+	if err != nil {
+		logger.Log.Debug("Rejected invalid refresh token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	// Удалённый пользователь не может продлить сессию
+	user, err := h.users.Get(c.Request.Context(), claims.UserID())
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			logger.Log.WithFields(logrus.Fields{"user_id": claims.UserID()}).Debug("Refresh token of unknown user")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		logger.Log.WithError(err).Debug("Error fetching user from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	h.respondTokens(c, http.StatusOK, user)
+}
+
+// Me godoc
+// @Summary      Текущий пользователь
+// @Description  Возвращает пользователя, его роль и разрешения
+// @Tags         Auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  meResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /auth/me [get]
+func (h *AuthHandler) Me(c *gin.Context) {
+	logger.Log.Debug("Entering Me handler")
+
+	principal, _ := auth.PrincipalFrom(c)
+	user, err := h.users.Get(c.Request.Context(), principal.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
+			return
+		}
+		logger.Log.WithError(err).Debug("Error fetching user from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, meResponse{User: *user, Permissions: auth.RolePermissions(user.Role)})
+}
+
+func (h *AuthHandler) respondTokens(c *gin.Context, status int, user *models.User) {
+	tokens, err := h.tokens.Issue(user)
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to issue tokens")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue tokens"})
+		return
+	}
+	c.JSON(status, authResponse{User: *user, TokenPair: *tokens})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"music-library/internal/auth"
+	"music-library/internal/middleware"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authAPI — testAPI с аутентификацией и проверкой разрешений, как в
+// api.SetupRouter, но без квот
+type authAPI struct {
+	*testAPI
+	users  *repository.MemoryUserRepository
+	keys   *repository.MemoryAPIKeyRepository
+	tokens *auth.TokenService
+}
+
+func newAuthAPI(t *testing.T) *authAPI {
+	t.Helper()
+
+	a := &authAPI{
+		testAPI: newTestAPI(t, SongHandlerConfig{}),
+		users:   repository.NewMemoryUserRepository(),
+		tokens:  auth.NewTokenService([]byte("0123456789abcdef0123456789abcdef"), time.Minute, time.Hour),
+	}
+	a.keys = repository.NewMemoryAPIKeyRepository(a.users)
+
+	h := NewSongHandler(a.songs, a.artists, a.albums, a.enricher, SongHandlerConfig{})
+	authh := NewAuthHandler(a.users, a.tokens)
+	kh := NewAPIKeyHandler(a.keys)
+	uh := NewUserHandler(a.users)
+
+	r := gin.New()
+	r.Use(middleware.Authenticate(a.tokens, a.keys, a.users, nil))
+	r.POST("/auth/register", authh.Register)
+	r.POST("/auth/login", authh.Login)
+	r.POST("/auth/refresh", authh.Refresh)
+	r.GET("/auth/me", middleware.RequireUser(), authh.Me)
+
+	r.GET("/api-keys", middleware.Authorize(auth.PermKeysManage), kh.GetAPIKeys)
+	r.POST("/api-keys", middleware.Authorize(auth.PermKeysManage), kh.AddAPIKey)
+	r.POST("/api-keys/:id/rotate", middleware.Authorize(auth.PermKeysManage), kh.RotateAPIKey)
+	r.DELETE("/api-keys/:id", middleware.Authorize(auth.PermKeysManage), kh.RevokeAPIKey)
+
+	r.GET("/users", middleware.Authorize(auth.PermUsersManage), uh.GetUsers)
+	r.PUT("/users/:id/role", middleware.Authorize(auth.PermUsersManage), uh.SetUserRole)
+	r.GET("/users/:id/role-changes", middleware.Authorize(auth.PermUsersManage), uh.GetRoleChanges)
+	r.DELETE("/users/:id", middleware.Authorize(auth.PermUsersManage), uh.DeleteUser)
+
+	r.GET("/songs", middleware.Authorize(auth.PermSongsRead), h.GetSongs)
+	r.POST("/songs", middleware.Authorize(auth.PermSongsWrite), h.AddSong)
+	r.DELETE("/songs/:id", middleware.Authorize(auth.PermSongsDelete), h.DeleteSong)
+
+	a.router = r
+	return a
+}
+
+// user создаёт пользователя с ролью role и возвращает его access-токен.
+// Самый первый пользователь хранилища становится админом независимо от role.
+func (a *authAPI) user(username, role string) (*models.User, string) {
+	a.t.Helper()
+
+	ctx := context.Background()
+	u := &models.User{Username: username, PasswordHash: "-", Role: role}
+	if err := a.users.Create(ctx, u); err != nil {
+		a.t.Fatal(err)
+	}
+	if u.Role != role {
+		if _, err := a.users.SetRole(ctx, u.ID, role, u); err != nil {
+			a.t.Fatal(err)
+		}
+		u.Role = role
+	}
+	pair, err := a.tokens.Issue(u)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return u, pair.AccessToken
+}
+
+func bearer(token string) []string {
+	return []string{"Authorization", "Bearer " + token}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	api := newAuthAPI(t)
+
+	w := api.do(http.MethodPost, "/auth/register", map[string]string{"username": "Alice", "password": "correct horse"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: status %d, body %s", w.Code, w.Body)
+	}
+	var registered authResponse
+	decode(t, w, &registered)
+	if registered.User.Role != auth.RoleAdmin || registered.AccessToken == "" || registered.RefreshToken == "" {
+		t.Errorf("first user = %+v", registered)
+	}
+
+	if w := api.do(http.MethodPost, "/auth/register", map[string]string{"username": "alice ", "password": "another password"}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate username: status %d, want 409", w.Code)
+	}
+	for _, body := range []map[string]string{
+		{"username": "al", "password": "correct horse"},
+		{"username": "bob", "password": "short"},
+	} {
+		if w := api.do(http.MethodPost, "/auth/register", body); w.Code != http.StatusBadRequest {
+			t.Errorf("register %v: status %d, want 400", body, w.Code)
+		}
+	}
+
+	w = api.do(http.MethodPost, "/auth/login", map[string]string{"username": "ALICE", "password": "correct horse"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", w.Code, w.Body)
+	}
+	var loggedIn authResponse
+	decode(t, w, &loggedIn)
+	if w := api.do(http.MethodGet, "/auth/me", nil, bearer(loggedIn.AccessToken)...); w.Code != http.StatusOK {
+		t.Errorf("me: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestLoginRejectsInvalidCredentials(t *testing.T) {
+	api := newAuthAPI(t)
+	if w := api.do(http.MethodPost, "/auth/register", map[string]string{"username": "alice", "password": "correct horse"}); w.Code != http.StatusCreated {
+		t.Fatalf("register: status %d", w.Code)
+	}
+
+	for _, body := range []map[string]string{
+		{"username": "alice", "password": "wrong password"},
+		{"username": "mallory", "password": "correct horse"},
+	} {
+		w := api.do(http.MethodPost, "/auth/login", body)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("login %v: status %d, want 401", body, w.Code)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	api := newAuthAPI(t)
+	user, access := api.user("alice", auth.RoleAdmin)
+	pair, err := api.tokens.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := api.do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": pair.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d, body %s", w.Code, w.Body)
+	}
+
+	// Access-токен не обменивается на новую пару
+	if w := api.do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": access}); w.Code != http.StatusUnauthorized {
+		t.Errorf("access token as refresh token: status %d, want 401", w.Code)
+	}
+	if w := api.do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": "garbage"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("garbage token: status %d, want 401", w.Code)
+	}
+
+	// Удалённый пользователь не продлевает сессию
+	other, _ := api.user("bob", auth.RoleReader)
+	otherPair, _ := api.tokens.Issue(other)
+	if err := api.users.Delete(context.Background(), other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if w := api.do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": otherPair.RefreshToken}); w.Code != http.StatusUnauthorized {
+		t.Errorf("deleted user: status %d, want 401", w.Code)
+	}
+}
+
+func TestAuthenticateRejectsInvalidCredentials(t *testing.T) {
+	api := newAuthAPI(t)
+	user, access := api.user("alice", auth.RoleAdmin)
+	pair, _ := api.tokens.Issue(user)
+
+	tests := []struct {
+		name    string
+		headers []string
+	}{
+		{"refresh token", bearer(pair.RefreshToken)},
+		{"garbage token", bearer("garbage")},
+		{"unknown key", []string{"X-API-Key", "ml_unknown"}},
+		{"basic scheme", []string{"Authorization", "Basic " + access}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := api.do(http.MethodGet, "/songs", nil, tt.headers...)
+			if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("status %d, headers %v; want 401 with WWW-Authenticate", w.Code, w.Header())
+			}
+		})
+	}
+
+	if w := api.do(http.MethodGet, "/songs", nil); w.Code != http.StatusOK {
+		t.Errorf("anonymous read: status %d", w.Code)
+	}
+	if w := api.do(http.MethodPost, "/songs", map[string]string{"group": "Muse", "song": "Uprising"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous write: status %d, want 401", w.Code)
+	}
+	if w := api.do(http.MethodPost, "/songs", map[string]string{"group": "Muse", "song": "Uprising"}, bearer(access)...); w.Code != http.StatusAccepted {
+		t.Errorf("authenticated write: status %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// songETag — сильный ETag песни, построенный из хеша её JSON-представления.
+// Названия группы и альбома берутся из справочников и меняются без смены
+// версии песни, поэтому одной версии для ETag недостаточно.
+func songETag(song *models.Song) string {
+	body, err := json.Marshal(song)
+	if err != nil {
+		// Песня кодируется всегда; версия — запасной вариант на всякий случай
+		return `"v` + strconv.Itoa(song.Version) + `"`
+	}
+	return `"` + bodyHash(body) + `"`
+}
+
+func setSongETag(c *gin.Context, song *models.Song) {
+	c.Header("ETag", songETag(song))
+}
+
+// contentETag — слабый ETag ответа, построенный из хеша его тела.
+// Используется для списков, у которых нет собственной версии.
+func contentETag(body []byte) string {
+	return `W/"` + bodyHash(body) + `"`
+}
+
+// bodyHash — укороченный SHA-256 тела ответа для ETag
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+// notModified выставляет заголовки кэширования и проверяет условный GET.
+// If-None-Match сравнивается слабо и имеет приоритет над If-Modified-Since.
+// Если представление у клиента актуально, отвечает 304 и возвращает true.
+func (h *SongHandler) notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(h.cfg.CacheMaxAge.Seconds()))+", must-revalidate")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	fresh := false
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		fresh = etagMatchesWeak(header, etag)
+	} else if header := c.GetHeader("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		// Last-Modified передаётся с точностью до секунды
+		since, err := http.ParseTime(header)
+		fresh = err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	if fresh {
+		c.Status(http.StatusNotModified)
+	}
+	return fresh
+}
+
+// etagMatchesWeak — слабое сравнение для If-None-Match: префикс W/ игнорируется
+func etagMatchesWeak(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatches сравнивает список ETag из If-Match со значением etag.
+// If-Match использует сильное сравнение, поэтому слабые теги W/"..." не совпадают.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch проверяет предусловие If-Match перед изменением песни и
+// возвращает ожидаемую версию для атомарной проверки в хранилище
+// (0 — заголовка нет, версия не проверяется). current — уже прочитанная песня
+// или nil. Отвечает 428 в строгом режиме без заголовка, 412 при устаревшем
+// ETag, 404 если песни нет, и тогда возвращает false.
+func (h *SongHandler) checkIfMatch(c *gin.Context, id int, current *models.Song) (int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if h.cfg.RequireIfMatch {
+			logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Missing If-Match precondition")
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+			return 0, false
+		}
+		return 0, true
+	}
+
+	if current == nil {
+		var err error
+		if current, err = h.repo.Get(c.Request.Context(), id); err != nil {
+			respondVersionError(c, err, id, "Error fetching song")
+			return 0, false
+		}
+	}
+
+	if !etagMatches(header, songETag(current)) {
+		// Текущий ETag позволяет клиенту понять, что песня изменилась
+		setSongETag(c, current)
+		respondVersionError(c, repository.ErrVersionConflict, id, "")
+		return 0, false
+	}
+	return current.Version, true
+}
+
+// respondVersionError переводит ошибки условного изменения песни в HTTP-ответы.
+// Для неизвестных ошибок отвечает 500 с сообщением fallback.
+func respondVersionError(c *gin.Context, err error, id int, fallback string) {
+	fields := logrus.Fields{"song_id": id}
+	switch {
+	case errors.Is(err, repository.ErrSongNotFound):
+		logger.Log.WithFields(fields).Debug("Song not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+	case errors.Is(err, repository.ErrVersionConflict):
+		logger.Log.WithFields(fields).Debug("Song was modified by another request")
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Song was modified, fetch it again"})
+	default:
+		logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestConditionalGetSong(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising", "album": "The Resistance"})
+
+	w := api.do(http.MethodGet, songPath(created.ID), nil)
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("missing validators: ETag %q, Last-Modified %q", etag, lastModified)
+	}
+
+	if w := api.do(http.MethodGet, songPath(created.ID), nil, "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match with current ETag: status %d, want 304", w.Code)
+	}
+	if w := api.do(http.MethodGet, songPath(created.ID), nil, "If-None-Match", "W/"+etag); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match with weak ETag: status %d, want 304", w.Code)
+	}
+	if w := api.do(http.MethodGet, songPath(created.ID), nil, "If-Modified-Since", lastModified); w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: status %d, want 304", w.Code)
+	}
+	if w := api.do(http.MethodGet, songPath(created.ID), nil, "If-None-Match", `"stale"`); w.Code != http.StatusOK {
+		t.Errorf("If-None-Match with stale ETag: status %d, want 200", w.Code)
+	}
+}
+
+// Названия группы и альбома приходят из справочников, поэтому их
+// переименование должно менять ETag песни
+func TestSongETagChangesWhenArtistOrAlbumRenamed(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising", "album": "The Resistance"})
+
+	etag := api.do(http.MethodGet, songPath(created.ID), nil).Header().Get("ETag")
+
+	w := api.do(http.MethodPut, "/artists/"+strconv.Itoa(created.ArtistID), map[string]interface{}{"name": "MUSE"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("rename artist: status %d, body %s", w.Code, w.Body)
+	}
+	w = api.do(http.MethodGet, songPath(created.ID), nil, "If-None-Match", etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("after artist rename: status %d, want 200", w.Code)
+	}
+	if next := w.Header().Get("ETag"); next == etag {
+		t.Error("ETag did not change after artist rename")
+	}
+	etag = w.Header().Get("ETag")
+
+	w = api.do(http.MethodPut, "/albums/"+strconv.Itoa(*created.AlbumID), map[string]interface{}{
+		"artistId": created.ArtistID, "title": "The Resistance (Deluxe)",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("rename album: status %d, body %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, songPath(created.ID), nil, "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("after album rename: status %d, want 200", w.Code)
+	}
+}
+
+func TestIfMatchPreconditions(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{RequireIfMatch: true})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+	update := map[string]interface{}{"group": "Muse", "song": "Uprising", "link": "https://example.com"}
+
+	if w := api.do(http.MethodPut, songPath(created.ID), update); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("without If-Match: status %d, want 428", w.Code)
+	}
+
+	etag := api.do(http.MethodGet, songPath(created.ID), nil).Header().Get("ETag")
+	w := api.do(http.MethodPut, songPath(created.ID), update, "If-Match", etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("with current If-Match: status %d, body %s", w.Code, w.Body)
+	}
+	// ETag из ответа PUT совпадает с ETag следующего GET
+	if next := api.do(http.MethodGet, songPath(created.ID), nil).Header().Get("ETag"); w.Header().Get("ETag") != next {
+		t.Errorf("PUT ETag %q differs from GET ETag %q", w.Header().Get("ETag"), next)
+	}
+
+	w = api.do(http.MethodPut, songPath(created.ID), update, "If-Match", etag)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("with stale If-Match: status %d, want 412", w.Code)
+	}
+	if w.Header().Get("ETag") == "" {
+		t.Error("412 response has no current ETag")
+	}
+	if w := api.do(http.MethodDelete, songPath(created.ID), nil, "If-Match", "W/"+etag); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("weak If-Match: status %d, want 412", w.Code)
+	}
+}
+
+// Список проверяется только по ETag: после удаления песни он меняется,
+// хотя время последнего изменения видимых песен могло уменьшиться
+func TestConditionalGetSongs(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+	latest := api.addSong(map[string]interface{}{"group": "Muse", "song": "Hysteria"})
+
+	w := api.do(http.MethodGet, "/songs", nil)
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") != "" {
+		t.Fatalf("validators: ETag %q, Last-Modified %q", etag, w.Header().Get("Last-Modified"))
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match with current ETag: status %d, want 304", w.Code)
+	}
+
+	if w := api.do(http.MethodDelete, songPath(latest.ID), nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE: status %d, body %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, "/songs", nil, "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("after delete: status %d, want 200", w.Code)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if w := api.do(http.MethodGet, "/songs", nil, "If-Modified-Since", future); w.Code != http.StatusOK {
+		t.Errorf("If-Modified-Since is ignored for lists: status %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ExternalCacheHandler обслуживает эндпоинты /admin/external-cache для
+// администраторов
+type ExternalCacheHandler struct {
+	cache *services.SongInfoCache
+}
+
+func NewExternalCacheHandler(cache *services.SongInfoCache) *ExternalCacheHandler {
+	return &ExternalCacheHandler{cache: cache}
+}
+
+// cacheEntry — запись кеша внешнего API в ответе
+type cacheEntry struct {
+	Group string `json:"group"`
+	Song  string `json:"song"`
+	// Negative — API ответил отказом, Status хранит код ответа
+	Negative  bool         `json:"negative"`
+	Status    int          `json:"status,omitempty"`
+	Details   *models.Song `json:"details,omitempty"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+}
+
+// cacheResponse — состояние кеша внешнего API
+type cacheResponse struct {
+	// Capacity — наибольшее число записей; 0 — кеш отключён
+	Capacity  int          `json:"capacity"`
+	Size      int          `json:"size"`
+	Hits      uint64       `json:"hits"`
+	Misses    uint64       `json:"misses"`
+	Evictions uint64       `json:"evictions"`
+	Entries   []cacheEntry `json:"entries"`
+	Page      int          `json:"page"`
+	Limit     int          `json:"limit"`
+}
+
+// GetExternalCache godoc
+// @Summary      Состояние кеша внешнего API
+// @Description  Возвращает счётчики кеша ответов внешнего API и его записи от недавно использованных к давним
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        page   query   int  false  "Номер страницы" default(1)
+// @Param        limit  query   int  false  "Количество записей на странице" default(50)
+// @Success      200    {object}  cacheResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Router       /admin/external-cache [get]
+func (h *ExternalCacheHandler) GetExternalCache(c *gin.Context) {
+	logger.Log.Debug("Entering GetExternalCache handler")
+
+	page, limit, ok := parsePagination(c, "50")
+	if !ok {
+		return
+	}
+
+	stats := h.cache.Stats()
+	resp := cacheResponse{
+		Capacity:  h.cache.Capacity(),
+		Size:      h.cache.Len(),
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Entries:   []cacheEntry{},
+		Page:      page,
+		Limit:     limit,
+	}
+	entries := h.cache.Entries()
+	start := min((page-1)*limit, len(entries))
+	end := min(start+limit, len(entries))
+	for _, e := range entries[start:end] {
+		resp.Entries = append(resp.Entries, cacheEntry{
+			Group:     e.Group,
+			Song:      e.Song,
+			Negative:  e.Negative(),
+			Status:    e.Status,
+			Details:   e.Details,
+			ExpiresAt: e.ExpiresAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// FlushExternalCache godoc
+// @Summary      Очистка кеша внешнего API
+// @Description  Удаляет запись песни, если указаны group и song, иначе очищает весь кеш
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        group  query   string  false  "Название группы"
+// @Param        song   query   string  false  "Название песни"
+// @Success      200    {object}  map[string]int
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Router       /admin/external-cache [delete]
+func (h *ExternalCacheHandler) FlushExternalCache(c *gin.Context) {
+	logger.Log.Debug("Entering FlushExternalCache handler")
+
+	group, song := c.Query("group"), c.Query("song")
+	if (group == "") != (song == "") {
+		logger.Log.Debug("Only one of group and song is set")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both group and song are required to remove a single entry"})
+		return
+	}
+
+	removed := 0
+	if group != "" {
+		if h.cache.Forget(group, song) {
+			removed = 1
+		}
+	} else {
+		removed = h.cache.Flush()
+	}
+
+	logger.Log.WithFields(logrus.Fields{"group": group, "song": song, "removed": removed}).Info("External API cache flushed")
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"music-library/internal/logger"
+	"music-library/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler отдаёт состояние сервиса и его зависимостей
+type HealthHandler struct {
+	external *services.ExternalClient
+}
+
+func NewHealthHandler(external *services.ExternalClient) *HealthHandler {
+	return &HealthHandler{external: external}
+}
+
+// breakerStatus — состояние автомата защиты в ответе /health
+type breakerStatus struct {
+	State    string `json:"state" example:"closed"`
+	Failures int    `json:"failures"`
+	// RetryAfter — секунды до пробного запроса, пока автомат разомкнут
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// healthResponse — ответ /health
+type healthResponse struct {
+	// Status — ok или degraded, если внешний API недоступен
+	Status      string        `json:"status" example:"ok"`
+	ExternalAPI breakerStatus `json:"externalApi"`
+}
+
+// Health godoc
+// @Summary      Состояние сервиса
+// @Description  Возвращает состояние сервиса и автомата защиты внешнего API. Пока автомат не замкнут, статус — degraded: дополнение новых песен откладывается до восстановления API.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  healthResponse
+// @Router       /health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	logger.Log.Debug("Entering Health handler")
+
+	snap := h.external.Breaker().Snapshot()
+	resp := healthResponse{
+		Status: "ok",
+		ExternalAPI: breakerStatus{
+			State:      snap.State.String(),
+			Failures:   snap.Failures,
+			RetryAfter: int(math.Ceil(snap.RetryAfter.Seconds())),
+		},
+	}
+	if snap.State != services.BreakerClosed {
+		resp.Status = "degraded"
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Metrics godoc
+// @Summary      Метрики сервиса
+// @Description  Возвращает метрики в текстовом формате Prometheus
+// @Tags         Health
+// @Produce      plain
+// @Success      200  {string}  string
+// @Router       /metrics [get]
+func (h *HealthHandler) Metrics(c *gin.Context) {
+	logger.Log.Debug("Entering Metrics handler")
+
+	snap := h.external.Breaker().Snapshot()
+	var b strings.Builder
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("external_api_circuit_state", "gauge", "External API circuit breaker state: 0 closed, 1 half-open, 2 open.", int(snap.State))
+	metric("external_api_circuit_failures", "gauge", "Consecutive failed external API requests.", snap.Failures)
+	metric("external_api_circuit_opens_total", "counter", "Times the external API circuit breaker has opened.", snap.Opens)
+	metric("external_api_circuit_rejected_total", "counter", "External API requests rejected by the open circuit breaker.", snap.Rejected)
+
+	cache := h.external.Cache()
+	stats := cache.Stats()
+	metric("external_api_cache_entries", "gauge", "Entries in the external API response cache.", cache.Len())
+	metric("external_api_cache_hits_total", "counter", "External API cache hits.", stats.Hits)
+	metric("external_api_cache_misses_total", "counter", "External API cache misses.", stats.Misses)
+	metric("external_api_cache_evictions_total", "counter", "Entries evicted from the full external API cache.", stats.Evictions)
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// songCursor — содержимое непрозрачного курсора keyset-пагинации:
+// ключ сортировки крайней песни страницы и сортировка, для которой он получен
+type songCursor struct {
+	Values []string `json:"v"`
+	Sort   string   `json:"s,omitempty"`
+	Before bool     `json:"b,omitempty"`
+}
+
+func encodeCursor(cur songCursor) string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor разбирает курсор; пустая строка означает начало списка
+func decodeCursor(s string) (songCursor, error) {
+	var cur songCursor
+	if s == "" {
+		return cur, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, err
+	}
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return cur, err
+	}
+	if len(cur.Values) == 0 {
+		return cur, errors.New("cursor has no values")
+	}
+	return cur, nil
+}
+
+// pageURL возвращает URL текущего запроса с заменёнными query-параметрами.
+// Пустое значение удаляет параметр.
+func pageURL(c *gin.Context, params map[string]string) string {
+	u := *c.Request.URL
+	q := u.Query()
+	for k, v := range params {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
+
+// setLinkHeader выставляет заголовок Link (RFC 8288) со ссылками rel -> URL
+func setLinkHeader(c *gin.Context, links [][2]string) {
+	var parts []string
+	for _, l := range links {
+		parts = append(parts, fmt.Sprintf("<%s>; rel=%q", l[1], l[0]))
+	}
+	if len(parts) > 0 {
+		c.Header("Link", strings.Join(parts, ", "))
+	}
+}
+
+// pageLinks строит ссылки first/prev/next/last для постраничного режима
+func pageLinks(c *gin.Context, page, totalPages int) [][2]string {
+	link := func(p int) string { return pageURL(c, map[string]string{"page": strconv.Itoa(p)}) }
+
+	links := [][2]string{{"first", link(1)}}
+	if page > 1 {
+		links = append(links, [2]string{"prev", link(page - 1)})
+	}
+	if page < totalPages {
+		links = append(links, [2]string{"next", link(page + 1)})
+	}
+	if totalPages > 0 {
+		links = append(links, [2]string{"last", link(totalPages)})
+	}
+	return links
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cur := songCursor{Values: []string{"2009-09-07", "12"}, Sort: "release_date", Before: true}
+	got, err := decodeCursor(encodeCursor(cur))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sort != cur.Sort || got.Before != cur.Before || len(got.Values) != 2 || got.Values[1] != "12" {
+		t.Errorf("decoded %+v, want %+v", got, cur)
+	}
+
+	for _, s := range []string{"!!!", base64.RawURLEncoding.EncodeToString([]byte(`{"v":[]}`))} {
+		if _, err := decodeCursor(s); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded", s)
+		}
+	}
+}
+
+func TestListSongsWithCursor(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	for _, s := range []string{"E", "B", "D", "A", "C"} {
+		api.addSong(map[string]interface{}{"group": "Muse", "song": s})
+	}
+
+	var names []string
+	target := "/songs?sort=song&limit=2&cursor="
+	for i := 0; target != ""; i++ {
+		if i > 5 {
+			t.Fatal("cursor pagination does not terminate")
+		}
+		w := api.do(http.MethodGet, target, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d, body %s", target, w.Code, w.Body)
+		}
+		var resp struct {
+			Songs      []models.Song `json:"songs"`
+			NextCursor *string       `json:"next_cursor"`
+		}
+		decode(t, w, &resp)
+		for _, s := range resp.Songs {
+			names = append(names, s.SongName)
+		}
+		target = ""
+		if resp.NextCursor != nil {
+			target = "/songs?sort=song&limit=2&cursor=" + url.QueryEscape(*resp.NextCursor)
+		}
+	}
+	if got := len(names); got != 5 || names[0] != "A" || names[4] != "E" {
+		t.Errorf("pages = %v, want A..E", names)
+	}
+}
+
+// Подделанный курсор — ошибка клиента, а не сервера
+func TestListSongsRejectsTamperedCursor(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+
+	tests := []struct {
+		name, query string
+		cursor      songCursor
+	}{
+		{"not a number", "sort=track", songCursor{Values: []string{"x", "1"}, Sort: "track"}},
+		{"not a date", "sort=release_date", songCursor{Values: []string{"yesterday", "1"}, Sort: "release_date"}},
+		{"wrong length", "sort=song", songCursor{Values: []string{"Uprising"}, Sort: "song"}},
+		{"other sort", "sort=song", songCursor{Values: []string{"1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := api.do(http.MethodGet, "/songs?"+tt.query+"&cursor="+encodeCursor(tt.cursor), nil)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status %d, want 400; body %s", w.Code, w.Body)
+			}
+		})
+	}
+}
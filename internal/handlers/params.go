@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"music-library/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// parseID разбирает path-параметр :id. При ошибке отвечает 400
+// с сообщением "Invalid <entity> ID" и возвращает false.
+func parseID(c *gin.Context, entity string) (int, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{entity + "_id": idStr}).Debug("Invalid " + entity + " ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + entity + " ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePagination разбирает query-параметры page и limit.
+// При ошибке отвечает 400 и возвращает false.
+func parsePagination(c *gin.Context, defaultLimit string) (page, limit int, ok bool) {
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", defaultLimit)
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		logger.Log.WithFields(logrus.Fields{"page": pageStr}).Debug("Invalid page parameter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+		return 0, 0, false
+	}
+
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		logger.Log.WithFields(logrus.Fields{"limit": limitStr}).Debug("Invalid limit parameter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return 0, 0, false
+	}
+	return page, limit, true
+}
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PlaylistHandler обслуживает эндпоинты /playlists
+type PlaylistHandler struct {
+	repo  repository.PlaylistRepository
+	songs repository.SongRepository
+}
+
+func NewPlaylistHandler(repo repository.PlaylistRepository, songs repository.SongRepository) *PlaylistHandler {
+	return &PlaylistHandler{repo: repo, songs: songs}
+}
+
+// playlistInput — тело запросов на создание и изменение плейлиста
+type playlistInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// entryInput — тело запроса на добавление песни в плейлист.
+// Без position песня добавляется в конец.
+type entryInput struct {
+	SongID   int `json:"songId" binding:"required"`
+	Position int `json:"position"`
+}
+
+// moveInput — тело запроса на перемещение записи плейлиста
+type moveInput struct {
+	Position int `json:"position" binding:"required"`
+}
+
+// GetPlaylists godoc
+// @Summary      Получение списка плейлистов
+// @Description  Возвращает плейлисты с числом записей, без самих записей
+// @Tags         Playlists
+// @Param        page   query   int  false  "Номер страницы" default(1)
+// @Param        limit  query   int  false  "Количество элементов на странице" default(10)
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /playlists [get]
+func (h *PlaylistHandler) GetPlaylists(c *gin.Context) {
+	logger.Log.Debug("Entering GetPlaylists handler")
+
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
+
+	playlists, err := h.repo.List(c.Request.Context(), repository.PlaylistFilter{Limit: limit, Offset: (page - 1) * limit})
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching playlists from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching playlists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"playlists": playlists, "page": page, "limit": limit})
+	logger.Log.Info("Playlists fetched successfully")
+}
+
+// GetPlaylist godoc
+// @Summary      Получение плейлиста
+// @Description  Возвращает плейлист с записями по порядку позиций и данными песен без текстов.
+// @Description  Записи песен из корзины помечены available=false.
+// @Tags         Playlists
+// @Param        id   path      int  true  "ID плейлиста"
+// @Success      200  {object}  models.Playlist
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /playlists/{id} [get]
+func (h *PlaylistHandler) GetPlaylist(c *gin.Context) {
+	logger.Log.Debug("Entering GetPlaylist handler")
+
+	id, ok := parseID(c, "playlist")
+	if !ok {
+		return
+	}
+	h.respondPlaylist(c, http.StatusOK, id)
+}
+
+// AddPlaylist godoc
+// @Summary      Создание плейлиста
+// @Tags         Playlists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        playlist  body      playlistInput  true  "Данные плейлиста"
+// @Success      201       {object}  models.Playlist
+// @Failure      400       {object}  map[string]string
+// @Failure      401       {object}  map[string]string
+// @Failure      403       {object}  map[string]string
+// @Failure      429       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /playlists [post]
+func (h *PlaylistHandler) AddPlaylist(c *gin.Context) {
+	logger.Log.Debug("Entering AddPlaylist handler")
+
+	playlist, ok := bindPlaylist(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), playlist); err != nil {
+		h.respondError(c, err, 0, "Failed to save playlist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"playlist_id": playlist.ID}).Info("Playlist added successfully")
+	h.respondPlaylist(c, http.StatusCreated, playlist.ID)
+}
+
+// UpdatePlaylist godoc
+// @Summary      Изменение плейлиста
+// @Description  Изменяет название и описание плейлиста; записи не затрагиваются
+// @Tags         Playlists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id        path      int            true  "ID плейлиста"
+// @Param        playlist  body      playlistInput  true  "Новые данные плейлиста"
+// @Success      200       {object}  models.Playlist
+// @Failure      400       {object}  map[string]string
+// @Failure      401       {object}  map[string]string
+// @Failure      403       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      429       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /playlists/{id} [put]
+func (h *PlaylistHandler) UpdatePlaylist(c *gin.Context) {
+	logger.Log.Debug("Entering UpdatePlaylist handler")
+
+	id, ok := parseID(c, "playlist")
+	if !ok {
+		return
+	}
+
+	playlist, ok := bindPlaylist(c)
+	if !ok {
+		return
+	}
+
+	playlist.ID = id
+	if err := h.repo.Update(c.Request.Context(), playlist); err != nil {
+		h.respondError(c, err, id, "Failed to update playlist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"playlist_id": id}).Info("Playlist updated successfully")
+	h.respondPlaylist(c, http.StatusOK, id)
+}
+
+// DeletePlaylist godoc
+// @Summary      Удаление плейлиста
+// @Description  Удаляет плейлист вместе с записями; песни остаются в библиотеке
+// @Tags         Playlists
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID плейлиста"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /playlists/{id} [delete]
+func (h *PlaylistHandler) DeletePlaylist(c *gin.Context) {
+	logger.Log.Debug("Entering DeletePlaylist handler")
+
+	id, ok := parseID(c, "playlist")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Failed to delete playlist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"playlist_id": id}).Info("Playlist deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Playlist deleted successfully"})
+}
+
+// AddPlaylistEntry godoc
+// @Summary      Добавление песни в плейлист
+// @Description  Вставляет песню на позицию position, сдвигая последующие записи. Без position песня добавляется в конец.
+// @Description  Одна песня может входить в плейлист несколько раз; песню из корзины добавить нельзя.
+// @Tags         Playlists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id     path      int         true  "ID плейлиста"
+// @Param        entry  body      entryInput  true  "Песня и позиция"
+// @Success      201    {object}  models.Playlist
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /playlists/{id}/entries [post]
+func (h *PlaylistHandler) AddPlaylistEntry(c *gin.Context) {
+	logger.Log.Debug("Entering AddPlaylistEntry handler")
+
+	id, ok := parseID(c, "playlist")
+	if !ok {
+		return
+	}
+
+	var input entryInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Position < 0 {
+		logger.Log.WithError(err).Debug("Invalid input data for playlist entry")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	entry, err := h.repo.AddEntry(c.Request.Context(), id, input.SongID, input.Position)
+	if err != nil {
+		h.respondError(c, err, id, "Failed to add song to playlist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{
+		"playlist_id": id, "entry_id": entry.ID, "song_id": input.SongID, "position": entry.Position,
+	}).Info("Song added to playlist successfully")
+	h.respondPlaylist(c, http.StatusCreated, id)
+}
+
+// RemovePlaylistEntry godoc
+// @Summary      Удаление записи из плейлиста
+// @Description  Удаляет запись; последующие записи сдвигаются на одну позицию вверх
+// @Tags         Playlists
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id        path      int  true  "ID плейлиста"
+// @Param        entryId   path      int  true  "ID записи"
+// @Success      200       {object}  models.Playlist
+// @Failure      400       {object}  map[string]string
+// @Failure      401       {object}  map[string]string
+// @Failure      403       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      429       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /playlists/{id}/entries/{entryId} [delete]
+func (h *PlaylistHandler) RemovePlaylistEntry(c *gin.Context) {
+	logger.Log.Debug("Entering RemovePlaylistEntry handler")
+
+	id, entryID, ok := parseEntryID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveEntry(c.Request.Context(), id, entryID); err != nil {
+		h.respondError(c, err, id, "Failed to remove song from playlist")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"playlist_id": id, "entry_id": entryID}).Info("Song removed from playlist successfully")
+	h.respondPlaylist(c, http.StatusOK, id)
+}
+
+// MovePlaylistEntry godoc
+// @Summary      Перемещение записи плейлиста
+// @Description  Перемещает запись на позицию position от 1 до числа записей; записи между старой и новой позицией сдвигаются
+// @Tags         Playlists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id        path      int        true  "ID плейлиста"
+// @Param        entryId   path      int        true  "ID записи"
+// @Param        move      body      moveInput  true  "Новая позиция"
+// @Success      200       {object}  models.Playlist
+// @Failure      400       {object}  map[string]string
+// @Failure      401       {object}  map[string]string
+// @Failure      403       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      429       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /playlists/{id}/entries/{entryId}/move [post]
+func (h *PlaylistHandler) MovePlaylistEntry(c *gin.Context) {
+	logger.Log.Debug("Entering MovePlaylistEntry handler")
+
+	id, entryID, ok := parseEntryID(c)
+	if !ok {
+		return
+	}
+
+	var input moveInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Log.WithError(err).Debug("Invalid input data for playlist entry move")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := h.repo.MoveEntry(c.Request.Context(), id, entryID, input.Position); err != nil {
+		h.respondError(c, err, id, "Failed to move playlist entry")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{
+		"playlist_id": id, "entry_id": entryID, "position": input.Position,
+	}).Info("Playlist entry moved successfully")
+	h.respondPlaylist(c, http.StatusOK, id)
+}
+
+// bindPlaylist разбирает playlistInput. При ошибке отвечает 400 и возвращает false.
+func bindPlaylist(c *gin.Context) (*models.Playlist, bool) {
+	var input playlistInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Name) == "" {
+		logger.Log.WithError(err).Debug("Invalid input data for playlist")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return nil, false
+	}
+	return &models.Playlist{
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+	}, true
+}
+
+// parseEntryID разбирает path-параметры :id и :entryId
+func parseEntryID(c *gin.Context) (playlistID, entryID int, ok bool) {
+	playlistID, ok = parseID(c, "playlist")
+	if !ok {
+		return 0, 0, false
+	}
+	entryStr := c.Param("entryId")
+	entryID, err := strconv.Atoi(entryStr)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"entry_id": entryStr}).Debug("Invalid entry ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return 0, 0, false
+	}
+	return playlistID, entryID, true
+}
+
+// respondPlaylist отвечает плейлистом с записями и данными песен
+func (h *PlaylistHandler) respondPlaylist(c *gin.Context, status, id int) {
+	ctx := c.Request.Context()
+	playlist, err := h.repo.Get(ctx, id)
+	if err != nil {
+		h.respondError(c, err, id, "Error fetching playlist")
+		return
+	}
+
+	playlist.Entries, err = h.repo.Entries(ctx, id)
+	if err == nil && len(playlist.Entries) > 0 {
+		err = h.embedSongs(c, playlist.Entries)
+	}
+	if err != nil {
+		logger.Log.WithError(err).WithFields(logrus.Fields{"playlist_id": id}).Debug("Error fetching playlist entries from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching playlist"})
+		return
+	}
+
+	c.JSON(status, playlist)
+}
+
+// embedSongs подставляет в записи данные песен одним запросом, включая песни из корзины
+func (h *PlaylistHandler) embedSongs(c *gin.Context, entries []models.PlaylistEntry) error {
+	ids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.SongID)
+	}
+	songs, err := h.songs.List(c.Request.Context(), repository.SongFilter{
+		IDs:        ids,
+		Trash:      repository.WithTrashed,
+		OmitLyrics: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]*models.Song, len(songs))
+	for i := range songs {
+		byID[songs[i].ID] = &songs[i]
+	}
+	for i := range entries {
+		entries[i].Song = byID[entries[i].SongID]
+	}
+	return nil
+}
+
+// respondError переводит ошибки PlaylistRepository в HTTP-ответы.
+// Для неизвестных ошибок отвечает 500 с сообщением fallback.
+func (h *PlaylistHandler) respondError(c *gin.Context, err error, id int, fallback string) {
+	fields := logrus.Fields{"playlist_id": id}
+	switch {
+	case errors.Is(err, repository.ErrPlaylistNotFound):
+		logger.Log.WithFields(fields).Debug("Playlist not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+	case errors.Is(err, repository.ErrEntryNotFound):
+		logger.Log.WithFields(fields).Debug("Playlist entry not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist entry not found"})
+	case errors.Is(err, repository.ErrSongNotFound):
+		logger.Log.WithFields(fields).Debug("Playlist song not found in database")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Song not found"})
+	case errors.Is(err, repository.ErrPositionOutOfRange):
+		logger.Log.WithFields(fields).Debug("Playlist position out of range")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Position out of range"})
+	default:
+		logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// playlistAPI — testAPI с эндпоинтами /playlists поверх тех же хранилищ
+type playlistAPI struct {
+	*testAPI
+}
+
+func newPlaylistAPI(t *testing.T) *playlistAPI {
+	t.Helper()
+
+	api := newTestAPI(t, SongHandlerConfig{})
+	h := NewPlaylistHandler(repository.NewMemoryPlaylistRepository(api.songs), api.songs)
+	api.router.GET("/playlists/:id", h.GetPlaylist)
+	api.router.POST("/playlists", h.AddPlaylist)
+	api.router.POST("/playlists/:id/entries", h.AddPlaylistEntry)
+	api.router.DELETE("/playlists/:id/entries/:entryId", h.RemovePlaylistEntry)
+	api.router.POST("/playlists/:id/entries/:entryId/move", h.MovePlaylistEntry)
+	return &playlistAPI{api}
+}
+
+// playlist создаёт плейлист из песен с названиями names и возвращает его
+func (a *playlistAPI) playlist(names ...string) models.Playlist {
+	a.t.Helper()
+
+	w := a.do(http.MethodPost, "/playlists", map[string]interface{}{"name": "Mix"})
+	if w.Code != http.StatusCreated {
+		a.t.Fatalf("POST /playlists: status %d, body %s", w.Code, w.Body)
+	}
+	var p models.Playlist
+	decode(a.t, w, &p)
+	for _, name := range names {
+		song := a.addSong(map[string]interface{}{"group": "Muse", "song": name})
+		w := a.do(http.MethodPost, a.path(p.ID)+"/entries", map[string]interface{}{"songId": song.ID})
+		if w.Code != http.StatusCreated {
+			a.t.Fatalf("POST entries: status %d, body %s", w.Code, w.Body)
+		}
+		decode(a.t, w, &p)
+	}
+	return p
+}
+
+func (a *playlistAPI) path(id int) string {
+	return "/playlists/" + strconv.Itoa(id)
+}
+
+func (a *playlistAPI) entryPath(p models.Playlist, entryID int) string {
+	return a.path(p.ID) + "/entries/" + strconv.Itoa(entryID)
+}
+
+// order возвращает названия песен по порядку и проверяет, что позиции идут подряд с 1
+func order(t *testing.T, p models.Playlist) []string {
+	t.Helper()
+
+	names := make([]string, 0, len(p.Entries))
+	for i, e := range p.Entries {
+		if e.Position != i+1 {
+			t.Errorf("entry %d has position %d, want %d", e.ID, e.Position, i+1)
+		}
+		if e.Song == nil {
+			t.Fatalf("entry %d has no song", e.ID)
+		}
+		names = append(names, e.Song.SongName)
+	}
+	if p.EntryCount != len(p.Entries) {
+		t.Errorf("entryCount = %d, want %d", p.EntryCount, len(p.Entries))
+	}
+	return names
+}
+
+func TestMovePlaylistEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    int // индекс записи в плейлисте A, B, C
+		position int
+		want     []string
+	}{
+		{"last to first", 2, 1, []string{"C", "A", "B"}},
+		{"first to last", 0, 3, []string{"B", "C", "A"}},
+		{"middle to first", 1, 1, []string{"B", "A", "C"}},
+		{"middle to last", 1, 3, []string{"A", "C", "B"}},
+		{"same position", 1, 2, []string{"A", "B", "C"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newPlaylistAPI(t)
+			p := api.playlist("A", "B", "C")
+
+			w := api.do(http.MethodPost, api.entryPath(p, p.Entries[tt.entry].ID)+"/move", map[string]interface{}{"position": tt.position})
+			if w.Code != http.StatusOK {
+				t.Fatalf("move: status %d, body %s", w.Code, w.Body)
+			}
+			decode(t, w, &p)
+			if got := order(t, p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovePlaylistEntryErrors(t *testing.T) {
+	api := newPlaylistAPI(t)
+	p := api.playlist("A", "B", "C")
+	move := api.entryPath(p, p.Entries[0].ID) + "/move"
+
+	for _, position := range []interface{}{0, -1, 4, 100, "first"} {
+		if w := api.do(http.MethodPost, move, map[string]interface{}{"position": position}); w.Code != http.StatusBadRequest {
+			t.Errorf("position %v: status %d, want 400", position, w.Code)
+		}
+	}
+	if w := api.do(http.MethodPost, api.entryPath(p, 999)+"/move", map[string]interface{}{"position": 1}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown entry: status %d, want 404", w.Code)
+	}
+	if w := api.do(http.MethodPost, api.path(999)+"/entries/1/move", map[string]interface{}{"position": 1}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown playlist: status %d, want 404", w.Code)
+	}
+
+	// Неудачные перемещения не меняют порядок
+	var got models.Playlist
+	decode(t, api.do(http.MethodGet, api.path(p.ID), nil), &got)
+	if names := order(t, got); !reflect.DeepEqual(names, []string{"A", "B", "C"}) {
+		t.Errorf("order = %v", names)
+	}
+}
+
+func TestAddPlaylistEntryAtPosition(t *testing.T) {
+	api := newPlaylistAPI(t)
+	p := api.playlist("A", "B")
+	song := api.addSong(map[string]interface{}{"group": "Muse", "song": "C"})
+
+	for _, position := range []int{-1, 4} {
+		w := api.do(http.MethodPost, api.path(p.ID)+"/entries", map[string]interface{}{"songId": song.ID, "position": position})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("position %d: status %d, want 400", position, w.Code)
+		}
+	}
+	w := api.do(http.MethodPost, api.path(p.ID)+"/entries", map[string]interface{}{"songId": song.ID, "position": 1})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("add: status %d, body %s", w.Code, w.Body)
+	}
+	decode(t, w, &p)
+	if got := order(t, p); !reflect.DeepEqual(got, []string{"C", "A", "B"}) {
+		t.Errorf("order = %v", got)
+	}
+}
+
+func TestRemovePlaylistEntry(t *testing.T) {
+	api := newPlaylistAPI(t)
+	p := api.playlist("A", "B", "C", "D")
+
+	w := api.do(http.MethodDelete, api.entryPath(p, p.Entries[1].ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("remove: status %d, body %s", w.Code, w.Body)
+	}
+	removed := p.Entries[1].ID
+	decode(t, w, &p)
+	if got := order(t, p); !reflect.DeepEqual(got, []string{"A", "C", "D"}) {
+		t.Errorf("order = %v", got)
+	}
+	if w := api.do(http.MethodDelete, api.entryPath(p, removed), nil); w.Code != http.StatusNotFound {
+		t.Errorf("remove again: status %d, want 404", w.Code)
+	}
+}
+
+func TestPlaylistFollowsSongDeletion(t *testing.T) {
+	api := newPlaylistAPI(t)
+	p := api.playlist("A", "B", "C")
+	songID := p.Entries[1].SongID
+
+	// Песня в корзине остаётся в плейлисте, но недоступна
+	if w := api.do(http.MethodDelete, songPath(songID), nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE song: status %d, body %s", w.Code, w.Body)
+	}
+	decode(t, api.do(http.MethodGet, api.path(p.ID), nil), &p)
+	if got := order(t, p); !reflect.DeepEqual(got, []string{"A", "B", "C"}) {
+		t.Errorf("order = %v", got)
+	}
+	if p.Entries[1].Available {
+		t.Error("trashed song is available")
+	}
+	if w := api.do(http.MethodPost, api.path(p.ID)+"/entries", map[string]interface{}{"songId": songID}); w.Code != http.StatusBadRequest {
+		t.Errorf("add trashed song: status %d, want 400", w.Code)
+	}
+
+	// После окончательного удаления запись исчезает, позиции идут подряд
+	if w := api.do(http.MethodDelete, "/trash/"+strconv.Itoa(songID), nil); w.Code != http.StatusOK {
+		t.Fatalf("purge: status %d, body %s", w.Code, w.Body)
+	}
+	decode(t, api.do(http.MethodGet, api.path(p.ID), nil), &p)
+	if got := order(t, p); !reflect.DeepEqual(got, []string{"A", "C"}) {
+		t.Errorf("order = %v", got)
+	}
+	for _, e := range p.Entries {
+		if !e.Available {
+			t.Errorf("entry %d is unavailable", e.ID)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// revisionDiff — ответ сравнения двух правок песни
+type revisionDiff struct {
+	SongID int `json:"songId"`
+	From   int `json:"from"`
+	To     int `json:"to"`
+	// Changed — поля песни, отличающиеся между правками
+	Changed []string          `json:"changed"`
+	Lyrics  []models.DiffLine `json:"lyrics"`
+}
+
+// GetRevisions godoc
+// @Summary      История правок песни
+// @Description  Возвращает все правки песни по возрастанию номера: действие, автора, время и полный снимок песни
+// @Tags         Revisions
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  []models.SongRevision
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/{id}/revisions [get]
+func (h *SongHandler) GetRevisions(c *gin.Context) {
+	logger.Log.Debug("Entering GetRevisions handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	revisions, err := h.repo.Revisions(c.Request.Context(), id)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching song revisions from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching revisions"})
+		return
+	}
+	// У существующей песни всегда есть хотя бы правка создания
+	if len(revisions) == 0 {
+		logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Song not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"songId": id, "revisions": revisions})
+}
+
+// GetRevision godoc
+// @Summary      Получение правки песни
+// @Tags         Revisions
+// @Param        id   path      int  true  "ID песни"
+// @Param        rev  path      int  true  "Номер правки"
+// @Success      200  {object}  models.SongRevision
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/{id}/revisions/{rev} [get]
+func (h *SongHandler) GetRevision(c *gin.Context) {
+	logger.Log.Debug("Entering GetRevision handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+	rev, ok := parseRevision(c, c.Param("rev"), "rev")
+	if !ok {
+		return
+	}
+
+	revision, err := h.repo.Revision(c.Request.Context(), id, rev)
+	if err != nil {
+		respondRevisionError(c, err, id, rev, "Error fetching revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions godoc
+// @Summary      Сравнение правок песни
+// @Description  Построчный diff текста песни между двумя правками и список изменившихся полей.
+// @Description  По умолчанию to — последняя правка, from — предыдущая перед to.
+// @Description  Если тексты различаются слишком сильно для построчного сравнения, отвечает 422.
+// @Tags         Revisions
+// @Param        id    path    int  true   "ID песни"
+// @Param        from  query   int  false  "Номер исходной правки"
+// @Param        to    query   int  false  "Номер итоговой правки"
+// @Success      200   {object}  revisionDiff
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      422   {object}  map[string]string
+// @Failure      429   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /songs/{id}/revisions/diff [get]
+func (h *SongHandler) DiffRevisions(c *gin.Context) {
+	logger.Log.Debug("Entering DiffRevisions handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	var to, from int
+	if toStr := c.Query("to"); toStr != "" {
+		if to, ok = parseRevision(c, toStr, "to"); !ok {
+			return
+		}
+	} else {
+		revisions, err := h.repo.Revisions(c.Request.Context(), id)
+		if err != nil {
+			respondRevisionError(c, err, id, 0, "Error comparing revisions")
+			return
+		}
+		if len(revisions) == 0 {
+			respondRevisionError(c, repository.ErrSongNotFound, id, 0, "")
+			return
+		}
+		to = len(revisions)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, ok = parseRevision(c, fromStr, "from"); !ok {
+			return
+		}
+	} else {
+		from = max(to-1, 1)
+	}
+
+	fromRev, err := h.repo.Revision(c.Request.Context(), id, from)
+	if err != nil {
+		respondRevisionError(c, err, id, from, "Error comparing revisions")
+		return
+	}
+	toRev, err := h.repo.Revision(c.Request.Context(), id, to)
+	if err != nil {
+		respondRevisionError(c, err, id, to, "Error comparing revisions")
+		return
+	}
+
+	lyrics, err := models.DiffLines(lyricsLines(fromRev.Snapshot.Lyrics), lyricsLines(toRev.Snapshot.Lyrics))
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{"song_id": id, "from": from, "to": to}).Info("Revisions are too large to compare")
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Lyrics differ too much to compare"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisionDiff{
+		SongID:  id,
+		From:    from,
+		To:      to,
+		Changed: changedFields(fromRev.Snapshot, toRev.Snapshot),
+		Lyrics:  lyrics,
+	})
+}
+
+// RevertSong godoc
+// @Summary      Откат песни к правке
+// @Description  Возвращает песню к снимку указанной правки. Откат сам записывается новой правкой, история не переписывается.
+// @Tags         Revisions
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID песни"
+// @Param        rev  path      int  true  "Номер правки"
+// @Success      200  {object}  models.Song
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/{id}/revisions/{rev}/revert [post]
+func (h *SongHandler) RevertSong(c *gin.Context) {
+	logger.Log.Debug("Entering RevertSong handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+	rev, ok := parseRevision(c, c.Param("rev"), "rev")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Revert(c.Request.Context(), id, rev); err != nil {
+		respondRevisionError(c, err, id, rev, "Failed to revert song")
+		return
+	}
+
+	song, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		respondRevisionError(c, err, id, rev, "Failed to revert song")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"song_id": id, "revision": rev}).Info("Song reverted successfully")
+	setSongETag(c, song)
+	c.JSON(http.StatusOK, song)
+}
+
+// parseRevision разбирает номер правки. При ошибке отвечает 400 и возвращает false.
+func parseRevision(c *gin.Context, s, name string) (int, bool) {
+	rev, err := strconv.Atoi(s)
+	if err != nil || rev < 1 {
+		return 0, badParam(c, name, s)
+	}
+	return rev, true
+}
+
+// respondRevisionError переводит ошибки истории правок в HTTP-ответы
+func respondRevisionError(c *gin.Context, err error, id, rev int, fallback string) {
+	fields := logrus.Fields{"song_id": id, "revision": rev}
+	switch {
+	case errors.Is(err, repository.ErrSongNotFound):
+		logger.Log.WithFields(fields).Debug("Song not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+	case errors.Is(err, repository.ErrRevisionNotFound):
+		logger.Log.WithFields(fields).Debug("Revision not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	case errors.Is(err, repository.ErrArtistNotFound):
+		logger.Log.WithFields(fields).Debug("Revision artist no longer exists")
+		c.JSON(http.StatusConflict, gin.H{"error": "Revision artist no longer exists"})
+	default:
+		logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// changedFields перечисляет поля песни, различающиеся в двух снимках
+func changedFields(a, b models.Song) []string {
+	changed := []string{}
+	add := func(name string, differ bool) {
+		if differ {
+			changed = append(changed, name)
+		}
+	}
+	add("group", a.ArtistID != b.ArtistID)
+	add("song", a.SongName != b.SongName)
+	add("album", !equalIntPtr(a.AlbumID, b.AlbumID))
+	add("trackNumber", !equalIntPtr(a.TrackNumber, b.TrackNumber))
+	add("releaseDate", a.ReleaseDate != b.ReleaseDate)
+	add("lyrics", a.Lyrics != b.Lyrics)
+	add("link", a.Link != b.Link)
+	add("deleted", (a.DeletedAt == nil) != (b.DeletedAt == nil))
+	return changed
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// lyricsLines разбивает текст песни на строки для сравнения
+func lyricsLines(lyrics string) []string {
+	lyrics = strings.ReplaceAll(lyrics, "\r\n", "\n")
+	if lyrics == "" {
+		return nil
+	}
+	return strings.Split(lyrics, "\n")
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+func TestRevisionsDiffAndRevert(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising", "lyrics": "first\nsecond"})
+	w := api.do(http.MethodPut, songPath(created.ID), map[string]interface{}{
+		"group": "Muse", "song": "Uprising", "lyrics": "first\nchanged\nthird", "link": "https://example.com",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: status %d, body %s", w.Code, w.Body)
+	}
+
+	var history struct {
+		Revisions []models.SongRevision `json:"revisions"`
+	}
+	decode(t, api.do(http.MethodGet, songPath(created.ID)+"/revisions", nil), &history)
+	revisions := history.Revisions
+	if len(revisions) != 2 || revisions[0].Action != models.RevisionCreate || revisions[1].Action != models.RevisionUpdate {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+
+	w = api.do(http.MethodGet, songPath(created.ID)+"/revisions/diff", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("diff: status %d, body %s", w.Code, w.Body)
+	}
+	var diff revisionDiff
+	decode(t, w, &diff)
+	if diff.From != 1 || diff.To != 2 || strings.Join(diff.Changed, ",") != "lyrics,link" {
+		t.Errorf("diff = %+v", diff)
+	}
+	want := []models.DiffLine{
+		{Op: models.DiffEqual, Text: "first"},
+		{Op: models.DiffDelete, Text: "second"},
+		{Op: models.DiffInsert, Text: "changed"},
+		{Op: models.DiffInsert, Text: "third"},
+	}
+	if len(diff.Lyrics) != len(want) {
+		t.Fatalf("lyrics diff = %+v", diff.Lyrics)
+	}
+	for i := range want {
+		if diff.Lyrics[i] != want[i] {
+			t.Errorf("lyrics diff line %d = %+v, want %+v", i, diff.Lyrics[i], want[i])
+		}
+	}
+
+	// Откат возвращает снимок и сам записывается новой правкой
+	w = api.do(http.MethodPost, songPath(created.ID)+"/revisions/1/revert", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("revert: status %d, body %s", w.Code, w.Body)
+	}
+	var reverted models.Song
+	decode(t, w, &reverted)
+	if reverted.Lyrics != "first\nsecond" || reverted.Link != "" || reverted.Version <= created.Version+1 {
+		t.Errorf("reverted song = %+v", reverted)
+	}
+	if w.Header().Get("ETag") == "" {
+		t.Error("revert response has no ETag")
+	}
+	decode(t, api.do(http.MethodGet, songPath(created.ID)+"/revisions", nil), &history)
+	revisions = history.Revisions
+	if len(revisions) != 3 || revisions[2].Action != models.RevisionRevert {
+		t.Errorf("revisions after revert = %+v", revisions)
+	}
+	w = api.do(http.MethodGet, songPath(created.ID)+"/revisions/diff?from=1&to=3", nil)
+	decode(t, w, &diff)
+	if len(diff.Changed) != 0 {
+		t.Errorf("revert differs from revision 1: %v", diff.Changed)
+	}
+}
+
+func TestRevisionErrors(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+
+	tests := []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, songPath(created.ID) + "/revisions/0", http.StatusBadRequest},
+		{http.MethodGet, songPath(created.ID) + "/revisions/5", http.StatusNotFound},
+		{http.MethodGet, songPath(created.ID) + "/revisions/diff?to=5", http.StatusNotFound},
+		{http.MethodGet, songPath(999) + "/revisions/diff", http.StatusNotFound},
+		{http.MethodPost, songPath(created.ID) + "/revisions/5/revert", http.StatusNotFound},
+		{http.MethodPost, songPath(999) + "/revisions/1/revert", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := api.do(tt.method, tt.target, nil); w.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.target, w.Code, tt.status)
+		}
+	}
+}
+
+func TestDiffRevisionsTooLarge(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	// Тексты без общих строк: каждая строка уникальна
+	numbered := func(prefix string, n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = prefix + strconv.Itoa(i)
+		}
+		return strings.Join(lines, "\n")
+	}
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising", "lyrics": numbered("a", 3000)})
+	w := api.do(http.MethodPut, songPath(created.ID), map[string]interface{}{"group": "Muse", "song": "Uprising", "lyrics": numbered("b", 3000)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: status %d", w.Code)
+	}
+
+	if w := api.do(http.MethodGet, songPath(created.ID)+"/revisions/diff", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status %d, want 422", w.Code)
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/xlsx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// exportFlushRows — через сколько строк экспорт отправляется клиенту
+const exportFlushRows = 100
+
+// exportColumns — заголовки CSV и XLSX в порядке полей songRow
+var exportColumns = []string{"id", "group", "song", "album", "track_number", "release_date", "link", "lyrics"}
+
+// rowWriter записывает выгрузку в одном из форматов
+type rowWriter interface {
+	Write(row songRow) error
+	Flush() error
+	Close() error
+}
+
+// exportFormat описывает формат выгрузки
+type exportFormat struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer, lyrics bool) (rowWriter, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv": {"text/csv; charset=utf-8", "csv", newCSVRowWriter},
+	"ndjson": {"application/x-ndjson", "ndjson", func(w io.Writer, _ bool) (rowWriter, error) {
+		return &ndjsonRowWriter{enc: json.NewEncoder(w)}, nil
+	}},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", newXLSXRowWriter},
+}
+
+// ExportSongs godoc
+// @Summary      Выгрузка библиотеки
+// @Description  Потоково выгружает песни в CSV, NDJSON или XLSX. Поддерживает те же фильтры и сортировку, что и список песен; пагинация не применяется.
+// @Description  Файлы CSV и NDJSON можно загрузить обратно через POST /songs/import.
+// @Tags         Songs
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format          query  string  false  "Формат выгрузки" Enums(csv, ndjson, xlsx) default(csv)
+// @Param        include_lyrics  query  bool    false  "Включать тексты песен" default(false)
+// @Param        group   query   string  false  "Название группы"
+// @Param        song    query   string  false  "Название песни"
+// @Param        fuzzy   query   bool    false  "Нечёткий поиск по группе и песне" default(false)
+// @Param        artist_id  query  int  false  "ID исполнителя"
+// @Param        album   query   string  false  "Название альбома"
+// @Param        ids     query   string  false  "ID песен через запятую"
+// @Param        released_from  query  string  false  "Дата релиза не раньше, YYYY-MM-DD"
+// @Param        released_to    query  string  false  "Дата релиза не позже, YYYY-MM-DD"
+// @Param        has_lyrics     query  bool    false  "Только песни с текстом или без"
+// @Param        has_link       query  bool    false  "Только песни со ссылкой или без"
+// @Param        enrichment     query  string  false  "Состояние дополнения данными внешнего API" Enums(pending, enriched, failed)
+// @Param        sort    query   string  false  "Поля сортировки через запятую, минус — по убыванию"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/export [get]
+func (h *SongHandler) ExportSongs(c *gin.Context) {
+	logger.Log.Debug("Entering ExportSongs handler")
+
+	formatName := c.DefaultQuery("format", "csv")
+	format, ok := exportFormats[formatName]
+	if !ok {
+		badParam(c, "format", formatName)
+		return
+	}
+	includeLyrics, ok := queryBool(c, "include_lyrics")
+	if !ok {
+		return
+	}
+	lyrics := includeLyrics != nil && *includeLyrics
+
+	filter, ok := parseSongFilter(c)
+	if !ok {
+		return
+	}
+	filter.OmitLyrics = !lyrics
+
+	logger.Log.WithFields(logrus.Fields{"format": formatName, "lyrics": lyrics}).Info("Exporting songs")
+
+	// Заголовки ответа отправляются с первой строкой, чтобы ошибку до начала
+	// выгрузки можно было вернуть обычным ответом 500
+	var (
+		w    rowWriter
+		rows int
+	)
+	start := func() error {
+		c.Header("Content-Type", format.contentType)
+		c.Header("Content-Disposition", `attachment; filename="songs.`+format.extension+`"`)
+		c.Status(http.StatusOK)
+		var err error
+		w, err = format.newWriter(c.Writer, lyrics)
+		return err
+	}
+
+	err := h.repo.Each(c.Request.Context(), filter, func(song models.Song) error {
+		if w == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := w.Write(exportRow(song)); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil && w == nil {
+		err = start()
+	}
+	if err != nil {
+		if w == nil {
+			logger.Log.WithError(err).Debug("Error fetching songs for export from the database")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error exporting songs"})
+			return
+		}
+		// Ответ уже начат: остаётся оборвать выгрузку, клиент увидит неполный файл
+		logger.Log.WithError(err).WithFields(logrus.Fields{"rows": rows}).Error("Song export interrupted")
+		c.Abort()
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		logger.Log.WithError(err).Error("Failed to finish song export")
+		return
+	}
+	logger.Log.WithFields(logrus.Fields{"rows": rows}).Info("Songs exported successfully")
+}
+
+func exportRow(s models.Song) songRow {
+	return songRow{
+		ID:          s.ID,
+		Group:       s.GroupName,
+		Song:        s.SongName,
+		Album:       s.Album,
+		TrackNumber: s.TrackNumber,
+		ReleaseDate: s.ReleaseDate,
+		Link:        s.Link,
+		Lyrics:      s.Lyrics,
+	}
+}
+
+// columns возвращает заголовки таблицы; без текстов последний столбец отбрасывается
+func columns(lyrics bool) []string {
+	if lyrics {
+		return exportColumns
+	}
+	return exportColumns[:len(exportColumns)-1]
+}
+
+type csvRowWriter struct {
+	w      *csv.Writer
+	lyrics bool
+}
+
+func newCSVRowWriter(w io.Writer, lyrics bool) (rowWriter, error) {
+	cw := csv.NewWriter(w)
+	return &csvRowWriter{w: cw, lyrics: lyrics}, cw.Write(columns(lyrics))
+}
+
+func (w *csvRowWriter) Write(row songRow) error {
+	track := ""
+	if row.TrackNumber != nil {
+		track = strconv.Itoa(*row.TrackNumber)
+	}
+	record := []string{strconv.Itoa(row.ID), row.Group, row.Song, row.Album, track, row.ReleaseDate, row.Link}
+	if w.lyrics {
+		record = append(record, row.Lyrics)
+	}
+	return w.w.Write(record)
+}
+
+func (w *csvRowWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvRowWriter) Close() error { return w.Flush() }
+
+type ndjsonRowWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonRowWriter) Write(row songRow) error { return w.enc.Encode(row) }
+func (w *ndjsonRowWriter) Flush() error            { return nil }
+func (w *ndjsonRowWriter) Close() error            { return nil }
+
+type xlsxRowWriter struct {
+	w      *xlsx.Writer
+	lyrics bool
+}
+
+func newXLSXRowWriter(w io.Writer, lyrics bool) (rowWriter, error) {
+	xw, err := xlsx.NewWriter(w, "Songs")
+	if err != nil {
+		return nil, err
+	}
+	header := make([]interface{}, 0, len(exportColumns))
+	for _, name := range columns(lyrics) {
+		header = append(header, name)
+	}
+	return &xlsxRowWriter{w: xw, lyrics: lyrics}, xw.WriteRow(header...)
+}
+
+func (w *xlsxRowWriter) Write(row songRow) error {
+	var track interface{}
+	if row.TrackNumber != nil {
+		track = *row.TrackNumber
+	}
+	cells := []interface{}{row.ID, row.Group, row.Song, row.Album, track, row.ReleaseDate, row.Link}
+	if w.lyrics {
+		cells = append(cells, row.Lyrics)
+	}
+	return w.w.WriteRow(cells...)
+}
+
+func (w *xlsxRowWriter) Flush() error { return w.w.Flush() }
+func (w *xlsxRowWriter) Close() error { return w.w.Close() }
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func (a *testAPI) export(query string) []byte {
+	a.t.Helper()
+
+	w := a.do(http.MethodGet, "/songs/export"+query, nil)
+	if w.Code != http.StatusOK {
+		a.t.Fatalf("GET /songs/export%s: status %d, body %s", query, w.Code, w.Body)
+	}
+	return w.Body.Bytes()
+}
+
+func addExportSongs(api *testAPI) {
+	api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising", "album": "The Resistance", "trackNumber": 1, "releaseDate": "2009-09-07", "lyrics": "Paranoia is in bloom,\nthe PR transmissions will resume"})
+	api.addSong(map[string]interface{}{"group": "Muse", "song": "Hysteria", "releaseDate": "2003-12-01"})
+	api.addSong(map[string]interface{}{"group": "Radiohead", "song": "Creep", "lyrics": "When you were here before"})
+}
+
+func TestExportCSV(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	addExportSongs(api)
+
+	records, err := csv.NewReader(bytes.NewReader(api.export("?group=Muse&sort=song"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != "id,group,song,album,track_number,release_date,link" {
+		t.Fatalf("records = %q", records)
+	}
+	if records[1][2] != "Hysteria" || records[2][2] != "Uprising" || records[2][4] != "1" || records[2][3] != "The Resistance" {
+		t.Errorf("rows = %q", records[1:])
+	}
+
+	records, err = csv.NewReader(bytes.NewReader(api.export("?include_lyrics=true&released_from=2009-01-01"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][7] != "lyrics" || !strings.Contains(records[1][7], "\nthe PR transmissions") {
+		t.Errorf("records with lyrics = %q", records)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	addExportSongs(api)
+
+	var rows []songRow
+	scanner := bufio.NewScanner(bytes.NewReader(api.export("?format=ndjson&has_lyrics=true")))
+	for scanner.Scan() {
+		var row songRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	for _, row := range rows {
+		if row.Lyrics != "" {
+			t.Errorf("lyrics exported without include_lyrics: %+v", row)
+		}
+	}
+
+	body := api.export("?format=ndjson&group=Radiohead&include_lyrics=true")
+	var row songRow
+	if err := json.Unmarshal(body, &row); err != nil || row.Lyrics != "When you were here before" {
+		t.Errorf("row = %+v, %v", row, err)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	addExportSongs(api)
+
+	w := api.do(http.MethodGet, "/songs/export?format=xlsx&include_lyrics=true", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "songs.xlsx") {
+		t.Fatalf("status %d, headers %v", w.Code, w.Header())
+	}
+	body := w.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet []byte
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			sheet, _ = io.ReadAll(rc)
+			rc.Close()
+		}
+	}
+	if rows := strings.Count(string(sheet), "<row "); rows != 4 {
+		t.Errorf("sheet has %d rows, want header and 3 songs", rows)
+	}
+	if !strings.Contains(string(sheet), `<c r="H1" t="inlineStr"><is><t xml:space="preserve">lyrics</t>`) {
+		t.Errorf("no lyrics column in %s", sheet)
+	}
+}
+
+func TestExportEmptyAndInvalid(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	if body := api.export("?group=Nobody"); string(body) != "id,group,song,album,track_number,release_date,link\n" {
+		t.Errorf("empty export = %q", body)
+	}
+	for _, query := range []string{"?format=pdf", "?include_lyrics=maybe", "?sort=lyrics"} {
+		if w := api.do(http.MethodGet, "/songs/export"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
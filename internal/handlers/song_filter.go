@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// parseSongFilter разбирает query-параметры фильтрации и сортировки списка песен.
+// Пагинация разбирается отдельно. При ошибке отвечает 400 и возвращает false.
+func parseSongFilter(c *gin.Context) (repository.SongFilter, bool) {
+	filter := repository.SongFilter{
+		Group: c.Query("group"),
+		Song:  c.Query("song"),
+		Album: c.Query("album"),
+	}
+
+	var ok bool
+	if filter.ArtistID, ok = queryInt(c, "artist_id"); !ok {
+		return filter, false
+	}
+
+	fuzzy, ok := queryBool(c, "fuzzy")
+	if !ok {
+		return filter, false
+	}
+	filter.Fuzzy = fuzzy != nil && *fuzzy
+
+	thresholdStr := c.DefaultQuery("threshold", "0.3")
+	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		return filter, badParam(c, "threshold", thresholdStr)
+	}
+	filter.Threshold = threshold
+
+	if idsStr := c.Query("ids"); idsStr != "" {
+		for _, part := range strings.Split(idsStr, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || id < 1 {
+				return filter, badParam(c, "ids", idsStr)
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
+	}
+
+	if filter.ReleasedFrom, ok = queryDate(c, "released_from"); !ok {
+		return filter, false
+	}
+	if filter.ReleasedTo, ok = queryDate(c, "released_to"); !ok {
+		return filter, false
+	}
+	if filter.HasLyrics, ok = queryBool(c, "has_lyrics"); !ok {
+		return filter, false
+	}
+	if filter.HasLink, ok = queryBool(c, "has_link"); !ok {
+		return filter, false
+	}
+	if filter.Enrichment = c.Query("enrichment"); filter.Enrichment != "" && !models.ValidEnrichmentStatus(filter.Enrichment) {
+		return filter, badParam(c, "enrichment", filter.Enrichment)
+	}
+
+	sortStr := c.Query("sort")
+	if filter.Sort, err = repository.ParseSongSort(sortStr); err != nil {
+		logger.Log.WithError(err).WithFields(logrus.Fields{"sort": sortStr}).Debug("Invalid sort parameter")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid sort parameter",
+			"fields": repository.SongSortFields,
+		})
+		return filter, false
+	}
+	return filter, true
+}
+
+// queryInt разбирает необязательный целочисленный параметр; 0, если не задан
+func queryInt(c *gin.Context, name string) (int, bool) {
+	s := c.Query(name)
+	if s == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, badParam(c, name, s)
+	}
+	return v, true
+}
+
+// queryBool разбирает необязательный логический параметр; nil, если не задан
+func queryBool(c *gin.Context, name string) (*bool, bool) {
+	s := c.Query(name)
+	if s == "" {
+		return nil, true
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, badParam(c, name, s)
+	}
+	return &v, true
+}
+
+// queryDate проверяет необязательный параметр-дату в формате YYYY-MM-DD
+func queryDate(c *gin.Context, name string) (string, bool) {
+	s := c.Query(name)
+	if s == "" {
+		return "", true
+	}
+	if _, err := time.Parse(time.DateOnly, s); err != nil {
+		return "", badParam(c, name, s)
+	}
+	return s, true
+}
+
+// badParam отвечает 400 "Invalid <name> parameter" и возвращает false
+func badParam(c *gin.Context, name, value string) bool {
+	logger.Log.WithFields(logrus.Fields{name: value}).Debug("Invalid " + name + " parameter")
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " parameter"})
+	return false
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxImportSize ограничивает размер загружаемого файла
+	maxImportSize = 32 << 20
+	// maxImportLine ограничивает длину строки NDJSON вместе с текстом песни
+	maxImportLine = 1 << 20
+)
+
+// songRow — строка файла импорта и экспорта. В NDJSON поля называются как
+// в models.Song, в CSV — id, group, song, album, track_number, release_date,
+// link, lyrics. ID заполняется при экспорте и игнорируется при импорте,
+// чтобы выгруженный файл можно было загрузить обратно.
+type songRow struct {
+	ID          int    `json:"id,omitempty"`
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	Album       string `json:"album,omitempty"`
+	TrackNumber *int   `json:"trackNumber,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	Link        string `json:"link,omitempty"`
+	Lyrics      string `json:"lyrics,omitempty"`
+}
+
+// importResult — результат обработки строки файла
+type importResult struct {
+	// Row — номер строки файла; в CSV строка заголовка имеет номер 1
+	Row    int      `json:"row"`
+	Status string   `json:"status"`
+	ID     int      `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Статусы строк импорта
+const (
+	importValid    = "valid"
+	importInvalid  = "invalid"
+	importImported = "imported"
+	importSkipped  = "skipped"
+)
+
+// importReport — ответ POST /songs/import
+type importReport struct {
+	DryRun   bool `json:"dryRun"`
+	Total    int  `json:"total"`
+	Valid    int  `json:"valid"`
+	Invalid  int  `json:"invalid"`
+	Imported int  `json:"imported"`
+	Queued   int  `json:"queued"`
+	// Unqueued — ID песен, не поместившихся в очередь дополнения; они
+	// остаются в состоянии pending и попадут в неё при следующем обходе
+	Unqueued []int          `json:"unqueued,omitempty"`
+	Rows     []importResult `json:"rows"`
+}
+
+// csvColumns сопоставляет нормализованные заголовки CSV полям songRow
+var csvColumns = map[string]func(r *songRow, v string) error{
+	"id":    func(r *songRow, v string) error { return nil },
+	"group": func(r *songRow, v string) error { r.Group = v; return nil },
+	"song":  func(r *songRow, v string) error { r.Song = v; return nil },
+	"album": func(r *songRow, v string) error { r.Album = v; return nil },
+	"tracknumber": func(r *songRow, v string) error {
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("track_number must be an integer")
+		}
+		r.TrackNumber = &n
+		return nil
+	},
+	"releasedate": func(r *songRow, v string) error { r.ReleaseDate = v; return nil },
+	"lyrics":      func(r *songRow, v string) error { r.Lyrics = v; return nil },
+	"link":        func(r *songRow, v string) error { r.Link = v; return nil },
+}
+
+// ImportSongs godoc
+// @Summary      Массовый импорт песен
+// @Description  Импортирует песни из CSV (text/csv) или NDJSON (application/x-ndjson), переданных телом запроса или файлом file в multipart/form-data.
+// @Description  Каждая строка проверяется отдельно; ответ содержит результат по каждой строке. Песни сохраняются одной транзакцией.
+// @Description  По умолчанию при ошибках ничего не импортируется (on_error=abort); on_error=skip импортирует только корректные строки.
+// @Description  Внешний API не вызывается синхронно: enrich=queue ставит импортированные песни в фоновую очередь дополнения.
+// @Tags         Songs
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        format    query  string  false  "Формат файла, если его нельзя определить по Content-Type или расширению" Enums(csv, ndjson)
+// @Param        dry_run   query  bool    false  "Только проверить строки, ничего не сохраняя" default(false)
+// @Param        on_error  query  string  false  "Поведение при некорректных строках" Enums(abort, skip) default(abort)
+// @Param        enrich    query  string  false  "Дополнение данными внешнего API" Enums(skip, queue) default(skip)
+// @Param        file      formData  file  false  "Файл CSV или NDJSON"
+// @Success      200  {object}  importReport
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      413  {object}  map[string]string
+// @Failure      415  {object}  map[string]string
+// @Failure      422  {object}  importReport
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/import [post]
+func (h *SongHandler) ImportSongs(c *gin.Context) {
+	logger.Log.Debug("Entering ImportSongs handler")
+
+	dryRun, ok := queryBool(c, "dry_run")
+	if !ok {
+		return
+	}
+	onError := c.DefaultQuery("on_error", "abort")
+	if onError != "abort" && onError != "skip" {
+		badParam(c, "on_error", onError)
+		return
+	}
+	enrich := c.DefaultQuery("enrich", "skip")
+	if enrich != "skip" && enrich != "queue" {
+		badParam(c, "enrich", enrich)
+		return
+	}
+
+	body, format, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer body.Close()
+
+	var (
+		rows    []songRow
+		results []importResult
+		err     error
+	)
+	switch format {
+	case "csv":
+		rows, results, err = parseImportCSV(body)
+	case "ndjson":
+		rows, results, err = parseImportNDJSON(body)
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		logger.Log.WithError(err).Debug("Import file is too large")
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	}
+	if err != nil {
+		logger.Log.WithError(err).Debug("Failed to parse import file")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import file: " + err.Error()})
+		return
+	}
+
+	report := importReport{DryRun: dryRun != nil && *dryRun, Total: len(results), Rows: results}
+	for i := range results {
+		if results[i].Status == importValid {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+	}
+	logger.Log.WithFields(logrus.Fields{
+		"format": format, "total": report.Total, "invalid": report.Invalid, "dry_run": report.DryRun,
+	}).Info("Importing songs")
+
+	if report.DryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	if report.Invalid > 0 && onError == "abort" {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	songs := importSongs(rows)
+	if enrich == "queue" {
+		for _, s := range songs {
+			s.EnrichmentStatus = models.EnrichmentPending
+		}
+	}
+	if err := h.repo.CreateBatch(c.Request.Context(), songs); err != nil {
+		logger.Log.WithError(err).Debug("Failed to insert imported songs into database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import songs"})
+		return
+	}
+
+	i := 0
+	for r := range results {
+		switch results[r].Status {
+		case importValid:
+			results[r].Status, results[r].ID = importImported, songs[i].ID
+			i++
+		case importInvalid:
+			results[r].Status = importSkipped
+		}
+	}
+	report.Imported = len(songs)
+
+	if enrich == "queue" {
+		for _, s := range songs {
+			if !h.enricher.Enqueue(s.ID) {
+				report.Unqueued = append(report.Unqueued, s.ID)
+				continue
+			}
+			report.Queued++
+		}
+		if len(report.Unqueued) > 0 {
+			logger.Log.WithFields(logrus.Fields{"unqueued": len(report.Unqueued)}).Warn("Enrichment queue is full, songs will be queued by the next sweep")
+		}
+	}
+
+	logger.Log.WithFields(logrus.Fields{"imported": report.Imported, "queued": report.Queued}).Info("Songs imported successfully")
+	c.JSON(http.StatusOK, report)
+}
+
+// importSource возвращает тело файла импорта и его формат.
+// При ошибке отвечает клиенту и возвращает false.
+func importSource(c *gin.Context) (io.ReadCloser, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	format := c.Query("format")
+
+	var body io.ReadCloser = c.Request.Body
+	switch ct := c.ContentType(); {
+	case ct == "multipart/form-data":
+		header, err := c.FormFile("file")
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			logger.Log.WithError(err).Debug("Import file is too large")
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+			return nil, "", false
+		}
+		if err != nil {
+			logger.Log.WithError(err).Debug("Import file is missing in multipart form")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required"})
+			return nil, "", false
+		}
+		if format == "" {
+			switch strings.ToLower(filepath.Ext(header.Filename)) {
+			case ".csv":
+				format = "csv"
+			case ".ndjson", ".jsonl":
+				format = "ndjson"
+			}
+		}
+		if body, err = header.Open(); err != nil {
+			logger.Log.WithError(err).Debug("Failed to open uploaded import file")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required"})
+			return nil, "", false
+		}
+	case format != "":
+	case ct == "text/csv":
+		format = "csv"
+	case ct == "application/x-ndjson" || ct == "application/ndjson" || ct == "application/jsonl":
+		format = "ndjson"
+	}
+
+	if format != "csv" && format != "ndjson" {
+		body.Close()
+		logger.Log.WithFields(logrus.Fields{"content_type": c.ContentType(), "format": format}).Debug("Unsupported import format")
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported import format, use csv or ndjson"})
+		return nil, "", false
+	}
+	return body, format, true
+}
+
+// parseImportCSV читает CSV с заголовком. Ошибка возвращается, только если
+// файл нельзя разобрать целиком; ошибки отдельных строк попадают в результаты.
+func parseImportCSV(r io.Reader) ([]songRow, []importResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, []importResult{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	setters := make([]func(*songRow, string) error, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		key := strings.NewReplacer("_", "", " ", "", "\ufeff", "").Replace(strings.ToLower(name))
+		set, ok := csvColumns[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		if seen[key] {
+			return nil, nil, fmt.Errorf("duplicate column %q", name)
+		}
+		seen[key] = true
+		setters[i] = set
+	}
+	if !seen["group"] || !seen["song"] {
+		return nil, nil, errors.New("columns group and song are required")
+	}
+
+	var (
+		rows    []songRow
+		results = []importResult{}
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			results = append(results, importResult{Row: parseErr.StartLine, Status: importInvalid, Errors: []string{parseErr.Err.Error()}})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		var row songRow
+		var errs []string
+		for i, value := range record {
+			if err := setters[i](&row, strings.TrimSpace(value)); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		rows, results = appendImportRow(rows, results, row, line, errs)
+	}
+	return rows, results, nil
+}
+
+// parseImportNDJSON читает по одной песне в JSON на строку; пустые строки пропускаются
+func parseImportNDJSON(r io.Reader) ([]songRow, []importResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+
+	var (
+		rows    []songRow
+		results = []importResult{}
+	)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var row songRow
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&row); err != nil {
+			results = append(results, importResult{Row: line, Status: importInvalid, Errors: []string{"invalid JSON: " + err.Error()}})
+			continue
+		}
+		row.Group, row.Song, row.Album = strings.TrimSpace(row.Group), strings.TrimSpace(row.Song), strings.TrimSpace(row.Album)
+		rows, results = appendImportRow(rows, results, row, line, nil)
+	}
+	return rows, results, scanner.Err()
+}
+
+// appendImportRow проверяет строку и добавляет её результат. Корректные строки
+// добавляются в rows в том же порядке, что и их результаты.
+func appendImportRow(rows []songRow, results []importResult, row songRow, line int, errs []string) ([]songRow, []importResult) {
+	errs = append(errs, validateImportRow(row)...)
+	if len(errs) > 0 {
+		return rows, append(results, importResult{Row: line, Status: importInvalid, Errors: errs})
+	}
+	return append(rows, row), append(results, importResult{Row: line, Status: importValid})
+}
+
+func validateImportRow(row songRow) []string {
+	var errs []string
+	if models.CleanName(row.Group) == "" {
+		errs = append(errs, "group is required")
+	}
+	if models.CleanName(row.Song) == "" {
+		errs = append(errs, "song is required")
+	}
+	if row.ReleaseDate != "" {
+		if _, err := time.Parse(time.DateOnly, row.ReleaseDate); err != nil {
+			errs = append(errs, "release date must be YYYY-MM-DD")
+		}
+	}
+	if row.TrackNumber != nil {
+		if *row.TrackNumber < 1 {
+			errs = append(errs, "track number must be positive")
+		}
+		if models.CleanName(row.Album) == "" {
+			errs = append(errs, "track number requires album")
+		}
+	}
+	return errs
+}
+
+// importSongs строит песни из корректных строк. Исполнители и альбомы задаются
+// названиями: CreateBatch разрешает их в одной транзакции с песнями.
+func importSongs(rows []songRow) []*models.Song {
+	songs := make([]*models.Song, 0, len(rows))
+	for _, row := range rows {
+		song := &models.Song{
+			GroupName:   models.CleanName(row.Group),
+			SongName:    models.CleanName(row.Song),
+			ReleaseDate: row.ReleaseDate,
+			Lyrics:      row.Lyrics,
+			Link:        row.Link,
+		}
+		if album := models.CleanName(row.Album); album != "" {
+			song.Album, song.TrackNumber = album, row.TrackNumber
+		}
+		songs = append(songs, song)
+	}
+	return songs
+}
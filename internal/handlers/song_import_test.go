@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+const importCSV = `group,song,album,track_number,release_date
+Muse,Uprising,The Resistance,1,2009-09-07
+Muse,Resistance,The Resistance,2,2009-09-07
+Radiohead,Creep,,,
+`
+
+func (a *testAPI) importSongs(query, contentType, body string) (*importReport, int) {
+	a.t.Helper()
+
+	w := a.do(http.MethodPost, "/songs/import"+query, []byte(body), "Content-Type", contentType)
+	if w.Code != http.StatusOK && w.Code != http.StatusUnprocessableEntity {
+		return nil, w.Code
+	}
+	var report importReport
+	decode(a.t, w, &report)
+	return &report, w.Code
+}
+
+func TestImportCSV(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	report, status := api.importSongs("", "text/csv", importCSV)
+	if status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if report.Total != 3 || report.Imported != 3 || report.Invalid != 0 {
+		t.Errorf("report = %+v", report)
+	}
+
+	songs, err := api.songs.List(context.Background(), repository.SongFilter{Sort: []repository.SortField{{Field: "id"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(songs) != 3 {
+		t.Fatalf("stored %d songs, want 3", len(songs))
+	}
+	// Одинаковые исполнители и альбомы разрешаются в одну запись
+	if songs[0].ArtistID != songs[1].ArtistID || songs[0].AlbumID == nil || *songs[0].AlbumID != *songs[1].AlbumID {
+		t.Errorf("songs of one album got different artist or album: %+v, %+v", songs[0], songs[1])
+	}
+	if songs[0].Album != "The Resistance" || songs[2].AlbumID != nil {
+		t.Errorf("unexpected albums: %q, %v", songs[0].Album, songs[2].AlbumID)
+	}
+}
+
+func TestImportInvalidRows(t *testing.T) {
+	const file = `{"group":"Muse","song":"Uprising"}
+{"group":"","song":"No group"}
+{"group":"Muse","song":"Bad date","releaseDate":"07.09.2009"}
+`
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	report, status := api.importSongs("", "application/x-ndjson", file)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("on_error=abort: status %d, want 422", status)
+	}
+	if report.Invalid != 2 || report.Imported != 0 {
+		t.Errorf("abort report = %+v", report)
+	}
+	if artists, _ := api.artists.List(context.Background(), 0, 0); len(artists) != 0 {
+		t.Errorf("aborted import created %d artists", len(artists))
+	}
+
+	report, status = api.importSongs("?dry_run=true", "application/x-ndjson", file)
+	if status != http.StatusOK || !report.DryRun || report.Imported != 0 {
+		t.Errorf("dry run: status %d, report %+v", status, report)
+	}
+
+	report, status = api.importSongs("?on_error=skip", "application/x-ndjson", file)
+	if status != http.StatusOK || report.Imported != 1 {
+		t.Fatalf("on_error=skip: status %d, report %+v", status, report)
+	}
+	if report.Rows[0].Status != importImported || report.Rows[1].Status != importSkipped {
+		t.Errorf("row statuses = %q, %q", report.Rows[0].Status, report.Rows[1].Status)
+	}
+}
+
+func TestImportUnsupportedFormat(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	if _, status := api.importSongs("", "application/xml", "<songs/>"); status != http.StatusUnsupportedMediaType {
+		t.Errorf("status %d, want 415", status)
+	}
+}
+
+// multipartImport кодирует файл импорта с именем filename в multipart/form-data
+func multipartImport(t *testing.T, filename, content string) (string, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return mw.FormDataContentType(), body.String()
+}
+
+func TestImportMultipart(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	contentType, body := multipartImport(t, "songs.csv", importCSV)
+	report, status := api.importSongs("", contentType, body)
+	if status != http.StatusOK || report.Imported != 3 {
+		t.Fatalf("status %d, report %+v", status, report)
+	}
+
+	contentType, body = multipartImport(t, "songs.txt", importCSV)
+	if _, status := api.importSongs("", contentType, body); status != http.StatusUnsupportedMediaType {
+		t.Errorf("unknown extension: status %d, want 415", status)
+	}
+	if _, status := api.importSongs("", "multipart/form-data; boundary=x", "--x--\r\n"); status != http.StatusBadRequest {
+		t.Errorf("without file: status %d, want 400", status)
+	}
+}
+
+func TestImportTooLarge(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	huge := importCSV + strings.Repeat("Muse,Uprising\n", maxImportSize/len("Muse,Uprising\n")+1)
+
+	if _, status := api.importSongs("", "text/csv", huge); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("raw body: status %d, want 413", status)
+	}
+	contentType, body := multipartImport(t, "songs.csv", huge)
+	if _, status := api.importSongs("", contentType, body); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("multipart: status %d, want 413", status)
+	}
+	if n, _ := api.songs.Count(context.Background(), repository.SongFilter{}); n != 0 {
+		t.Errorf("%d songs imported from an oversize file", n)
+	}
+}
+
+func TestImportQueuesEnrichment(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	report, status := api.importSongs("?enrich=queue", "text/csv", importCSV)
+	if status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if report.Queued != 3 || len(report.Unqueued) != 0 {
+		t.Errorf("queued %d, unqueued %v", report.Queued, report.Unqueued)
+	}
+	song, err := api.songs.Get(context.Background(), report.Rows[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if song.EnrichmentStatus != models.EnrichmentPending {
+		t.Errorf("enrichment status = %q, want pending", song.EnrichmentStatus)
+	}
+}
+
+// Песни, не попавшие в переполненную очередь, перечисляются в отчёте
+func TestImportReportsUnqueuedSongs(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	for id := 1000; api.enricher.Enqueue(id); id++ {
+	}
+
+	report, status := api.importSongs("?enrich=queue", "text/csv", importCSV)
+	if status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if report.Queued != 0 || len(report.Unqueued) != 3 || report.Unqueued[0] != report.Rows[0].ID {
+		t.Errorf("queued %d, unqueued %v", report.Queued, report.Unqueued)
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/patch"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// PatchSong godoc
+// @Summary      Частичное изменение песни
+// @Description  Изменяет только переданные поля песни. Тип тела задаёт формат патча:
+// @Description  application/merge-patch+json (или application/json) — JSON Merge Patch (RFC 7396), null очищает поле;
+// @Description  application/json-patch+json — JSON Patch (RFC 6902). Поля id, artistId, version, createdAt, updatedAt и deletedAt изменить нельзя.
+// @Tags         Songs
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id     path      int     true  "ID песни"
+// @Param        If-Match  header  string  false  "ETag песни из GET; обязателен в строгом режиме"
+// @Param        patch  body      object  true  "Merge Patch или массив операций JSON Patch"
+// @Success      200    {object}  models.Song
+// @Header       200    {string}  ETag  "ETag изменённой песни"
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      409    {object}  map[string]string
+// @Failure      412    {object}  map[string]string
+// @Failure      415    {object}  map[string]string
+// @Failure      422    {object}  map[string]string
+// @Failure      428    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /songs/{id} [patch]
+func (h *SongHandler) PatchSong(c *gin.Context) {
+	logger.Log.Debug("Entering PatchSong handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	var apply func(doc, p []byte) ([]byte, error)
+	switch c.ContentType() {
+	case mergePatchType, "application/json":
+		apply = patch.Merge
+	case jsonPatchType:
+		apply = patch.Apply
+	default:
+		logger.Log.WithFields(logrus.Fields{"content_type": c.ContentType()}).Debug("Unsupported patch content type")
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported patch content type"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil || !json.Valid(body) {
+		logger.Log.WithError(err).Debug("Invalid input for patching song")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	current, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		respondVersionError(c, err, id, "Failed to update song")
+		return
+	}
+
+	version, ok := h.checkIfMatch(c, id, current)
+	if !ok {
+		return
+	}
+
+	song, status, message := patchSong(current, body, apply)
+	if song == nil {
+		logger.Log.WithFields(logrus.Fields{"song_id": id, "reason": message}).Debug("Failed to apply patch to song")
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	if !h.resolveArtist(c, song) || !h.resolveAlbum(c, song) {
+		return
+	}
+
+	song.Version = version
+	if err := h.repo.Update(c.Request.Context(), song); err != nil {
+		respondVersionError(c, err, id, "Failed to update song")
+		return
+	}
+
+	updated, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching patched song from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update song"})
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"song_id": id}).Info("Song patched successfully")
+	setSongETag(c, updated)
+	c.JSON(http.StatusOK, updated)
+}
+
+// patchSong применяет патч к JSON-представлению песни и проверяет результат.
+// При ошибке возвращает nil, HTTP-статус и сообщение для ответа.
+func patchSong(current *models.Song, body []byte, apply func(doc, p []byte) ([]byte, error)) (*models.Song, int, string) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to update song"
+	}
+
+	patched, err := apply(doc, body)
+	switch {
+	case errors.Is(err, patch.ErrTestFailed):
+		return nil, http.StatusConflict, "Patch test failed"
+	case err != nil:
+		return nil, http.StatusUnprocessableEntity, "Patch cannot be applied"
+	}
+
+	var song models.Song
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&song); err != nil {
+		return nil, http.StatusUnprocessableEntity, "Patched song is invalid"
+	}
+	if song.ID != current.ID || song.ArtistID != current.ArtistID || song.Version != current.Version ||
+		!song.CreatedAt.Equal(current.CreatedAt) || !song.UpdatedAt.Equal(current.UpdatedAt) || song.DeletedAt != nil {
+		return nil, http.StatusUnprocessableEntity, "Fields id, artistId, version, createdAt, updatedAt and deletedAt are read-only"
+	}
+
+	// Если клиент сменил группу или название альбома, не трогая albumId,
+	// альбом определяется заново по названию
+	if equalIntPtr(song.AlbumID, current.AlbumID) &&
+		(song.Album != current.Album || models.NormalizeName(song.GroupName) != models.NormalizeName(current.GroupName)) {
+		song.AlbumID = nil
+	}
+	return &song, http.StatusOK, ""
+}
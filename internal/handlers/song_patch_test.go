@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+func TestPatchSong(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{
+		"group": "Muse", "song": "Uprising", "lyrics": "Paranoia is in bloom", "link": "https://example.com",
+	})
+
+	// Merge Patch: null очищает поле, остальные поля не меняются
+	w := api.do(http.MethodPatch, songPath(created.ID), []byte(`{"link":null,"releaseDate":"2009-09-07"}`), "Content-Type", mergePatchType)
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge patch: status %d, body %s", w.Code, w.Body)
+	}
+	var got models.Song
+	decode(t, w, &got)
+	if got.Link != "" || got.ReleaseDate != "2009-09-07" || got.Lyrics != "Paranoia is in bloom" {
+		t.Errorf("after merge patch: %+v", got)
+	}
+
+	w = api.do(http.MethodPatch, songPath(created.ID), []byte(`[
+		{"op":"test","path":"/releaseDate","value":"2009-09-07"},
+		{"op":"replace","path":"/lyrics","value":null}
+	]`), "Content-Type", jsonPatchType)
+	if w.Code != http.StatusOK {
+		t.Fatalf("JSON patch: status %d, body %s", w.Code, w.Body)
+	}
+	decode(t, w, &got)
+	if got.Lyrics != "" || got.ReleaseDate != "2009-09-07" {
+		t.Errorf("after JSON patch: %+v", got)
+	}
+}
+
+func TestPatchSongErrors(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+
+	tests := []struct {
+		name, contentType, body string
+		status                  int
+	}{
+		{"unsupported type", "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"malformed body", mergePatchType, `{"link":`, http.StatusBadRequest},
+		{"failed test", jsonPatchType, `[{"op":"test","path":"/song","value":"Hysteria"}]`, http.StatusConflict},
+		{"missing path", jsonPatchType, `[{"op":"remove","path":"/nope"}]`, http.StatusUnprocessableEntity},
+		{"read-only field", mergePatchType, `{"id":42}`, http.StatusUnprocessableEntity},
+		{"unknown field", mergePatchType, `{"rating":5}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := api.do(http.MethodPatch, songPath(created.ID), []byte(tt.body), "Content-Type", tt.contentType)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+	if w := api.do(http.MethodPatch, songPath(42), []byte(`{}`), "Content-Type", mergePatchType); w.Code != http.StatusNotFound {
+		t.Errorf("unknown song: status %d, want 404", w.Code)
+	}
+}
@@ -1,138 +1,423 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
-	"music-library/internal/database"
 	"music-library/internal/logger"
 	"music-library/internal/models"
+	"music-library/internal/repository"
 	"music-library/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+// SongHandler обслуживает эндпоинты /songs поверх SongRepository
+type SongHandler struct {
+	repo     repository.SongRepository
+	artists  repository.ArtistRepository
+	albums   repository.AlbumRepository
+	enricher *services.Enricher
+	cfg      SongHandlerConfig
+}
+
+// SongHandlerConfig — настройки SongHandler
+type SongHandlerConfig struct {
+	// RequireIfMatch включает строгий режим: PUT, PATCH и DELETE песни
+	// без заголовка If-Match отклоняются с 428
+	RequireIfMatch bool
+	// CacheMaxAge — max-age в Cache-Control ответов GET; после него клиент
+	// перепроверяет ответ условным запросом
+	CacheMaxAge time.Duration
+	// SaveUnenriched — пока автомат защиты внешнего API разомкнут, POST /songs
+	// сохраняет песню в очередь на дополнение вместо ответа 503
+	SaveUnenriched bool
+}
+
+func NewSongHandler(repo repository.SongRepository, artists repository.ArtistRepository, albums repository.AlbumRepository, enricher *services.Enricher, cfg SongHandlerConfig) *SongHandler {
+	return &SongHandler{repo: repo, artists: artists, albums: albums, enricher: enricher, cfg: cfg}
+}
+
 // GetSongs godoc
 // @Summary      Получение песен с фильтрацией и пагинацией
-// @Description  Возвращает список песен с фильтрацией по названию группы и песни, а также поддерживает пагинацию
+// @Description  Возвращает список песен с фильтрацией по названию группы и песни, а также поддерживает пагинацию.
+// @Description  С fuzzy=true названия ищутся с учётом опечаток и сортируются по сходству. Если ничего не найдено, ответ содержит подсказку did_you_mean.
+// @Description  Поддерживает два режима пагинации: page/limit и курсорный (cursor/limit, стабилен при вставке новых песен). Пустой cursor открывает первую страницу; ссылки на соседние страницы возвращаются в заголовке Link.
+// @Description  Поддерживает условный GET по If-None-Match со слабым ETag ответа.
 // @Tags         Songs
 // @Param        group   query   string  false  "Название группы"
 // @Param        song    query   string  false  "Название песни"
+// @Param        fuzzy   query   bool    false  "Нечёткий поиск по группе и песне" default(false)
+// @Param        threshold  query  number  false  "Минимальное сходство для нечёткого поиска, от 0 до 1" default(0.3)
+// @Param        artist_id  query  int  false  "ID исполнителя"
+// @Param        album   query   string  false  "Название альбома"
+// @Param        ids     query   string  false  "ID песен через запятую"
+// @Param        released_from  query  string  false  "Дата релиза не раньше, YYYY-MM-DD"
+// @Param        released_to    query  string  false  "Дата релиза не позже, YYYY-MM-DD"
+// @Param        has_lyrics     query  bool    false  "Только песни с текстом или без"
+// @Param        has_link       query  bool    false  "Только песни со ссылкой или без"
+// @Param        enrichment     query  string  false  "Состояние дополнения данными внешнего API" Enums(pending, enriched, failed)
+// @Param        sort    query   string  false  "Поля сортировки через запятую, минус — по убыванию: id, group, song, album, track, release_date" example(release_date,-song)
 // @Param        page    query   int     false  "Номер страницы" default(1)
+// @Param        cursor  query   string  false  "Курсор из next_cursor или prev_cursor"
 // @Param        limit   query   int     false  "Количество элементов на странице" default(10)
+// @Param        If-None-Match      header  string  false  "ETag ранее полученного ответа"
 // @Success      200     {object}  []models.Song
+// @Success      304     "Ответ не изменился"
+// @Header       200     {string}  Link  "Ссылки на соседние страницы (RFC 8288)"
+// @Header       200     {string}  ETag  "Хеш ответа"
 // @Failure      400     {object}  map[string]string
+// @Failure      429     {object}  map[string]string
 // @Failure      500     {object}  map[string]string
 // @Router       /songs [get]
-func GetSongs(c *gin.Context) {
+func (h *SongHandler) GetSongs(c *gin.Context) {
 	logger.Log.Debug("Entering GetSongs handler")
 
-	var songs []models.Song
-	group := c.Query("group")
-	song := c.Query("song")
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		logger.Log.WithFields(logrus.Fields{"page": pageStr}).Debug("Invalid page parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+	filter, ok := parseSongFilter(c)
+	if !ok {
 		return
 	}
+	sortStr := repository.FormatSongSort(filter.Sort)
+
+	cursorStr, cursorMode := c.GetQuery("cursor")
+	var cursor songCursor
+	if cursorMode {
+		if filter.Fuzzy && len(filter.Sort) == 0 {
+			logger.Log.Debug("Cursor pagination requested with similarity ordering")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor pagination with fuzzy search requires the sort parameter"})
+			return
+		}
+		var err error
+		if cursor, err = decodeCursor(cursorStr); err != nil {
+			logger.Log.WithError(err).WithFields(logrus.Fields{"cursor": cursorStr}).Debug("Invalid cursor parameter")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor parameter"})
+			return
+		}
+		if cursorStr != "" && cursor.Sort != sortStr {
+			logger.Log.WithFields(logrus.Fields{"cursor_sort": cursor.Sort, "sort": sortStr}).Debug("Cursor does not match sort parameter")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor does not match sort parameter"})
+			return
+		}
+		if cursorStr != "" {
+			if err := repository.ValidateKeyset(filter, cursor.Values); err != nil {
+				logger.Log.WithError(err).WithFields(logrus.Fields{"cursor": cursorStr}).Debug("Invalid cursor parameter")
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor parameter"})
+				return
+			}
+		}
+	}
+
+	logger.Log.WithFields(logrus.Fields{
+		"group": filter.Group, "song": filter.Song, "album": filter.Album, "fuzzy": filter.Fuzzy,
+		"sort": sortStr, "page": page, "cursor": cursorStr, "limit": limit,
+	}).Info("Fetching songs with filters")
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		logger.Log.WithFields(logrus.Fields{"limit": limitStr}).Debug("Invalid limit parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+	total, err := h.repo.Count(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error counting songs in the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching songs"})
 		return
 	}
 
-	offset := (page - 1) * limit
-	query := "SELECT * FROM songs WHERE 1=1"
-	if group != "" {
-		query += " AND group_name ILIKE '%' || $1 || '%'"
+	if cursorMode {
+		// Запрашиваем на одну песню больше, чтобы узнать, есть ли следующая страница
+		filter.Limit = limit + 1
+		if cursorStr != "" {
+			filter.Keyset = &repository.Keyset{Values: cursor.Values, Before: cursor.Before}
+		}
+	} else {
+		filter.Limit = limit
+		filter.Offset = (page - 1) * limit
 	}
-	if song != "" {
-		query += " AND song_name ILIKE '%' || $2 || '%'"
+
+	songs, err := h.repo.List(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching songs from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching songs"})
+		return
 	}
-	query += " LIMIT $3 OFFSET $4"
 
-	logger.Log.WithFields(logrus.Fields{"group": group, "song": song, "page": page, "limit": limit}).Info("Fetching songs with filters")
+	response := gin.H{"limit": limit, "total": total}
+	if cursorMode {
+		hasMore := len(songs) > limit
+		if hasMore && cursor.Before {
+			songs = songs[1:]
+		} else if hasMore {
+			songs = songs[:limit]
+		}
+		hasNext, hasPrev := hasMore, cursorStr != ""
+		if cursor.Before {
+			hasNext, hasPrev = true, hasMore
+		}
+
+		var links [][2]string
+		response["next_cursor"], response["prev_cursor"] = nil, nil
+		if hasNext && len(songs) > 0 {
+			next := encodeCursor(songCursor{Values: repository.SongSortKey(songs[len(songs)-1], filter.Sort), Sort: sortStr})
+			response["next_cursor"] = next
+			links = append(links, [2]string{"next", pageURL(c, map[string]string{"cursor": next, "page": ""})})
+		}
+		if hasPrev && len(songs) > 0 {
+			prev := encodeCursor(songCursor{Values: repository.SongSortKey(songs[0], filter.Sort), Sort: sortStr, Before: true})
+			response["prev_cursor"] = prev
+			links = append(links, [2]string{"prev", pageURL(c, map[string]string{"cursor": prev, "page": ""})})
+		}
+		setLinkHeader(c, links)
+	} else {
+		totalPages := (total + limit - 1) / limit
+		response["page"] = page
+		response["total_pages"] = totalPages
+		setLinkHeader(c, pageLinks(c, page, totalPages))
+	}
+	response["songs"] = songs
+
+	if len(songs) == 0 && total == 0 && (filter.Group != "" || filter.Song != "") {
+		suggestion, err := h.repo.Suggest(c.Request.Context(), filter.Group, filter.Song)
+		if err != nil {
+			// Подсказка необязательна, поэтому ошибка не ломает ответ
+			logger.Log.WithError(err).Debug("Error fetching name suggestions from the database")
+		} else if suggestion != nil {
+			response["did_you_mean"] = suggestion
+		}
+	}
 
-	err = database.DB.Select(&songs, query, group, song, limit, offset)
+	body, err := json.Marshal(response)
 	if err != nil {
-		logger.Log.WithError(err).Debug("Error fetching songs from the database")
+		logger.Log.WithError(err).Debug("Failed to encode songs response")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching songs"})
 		return
 	}
+	// Last-Modified у списка нет: время последнего изменения видимых песен
+	// уменьшается после удаления, а переименование исполнителя его не меняет
+	if h.notModified(c, contentETag(body), time.Time{}) {
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"songs": songs, "page": page, "limit": limit})
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
 	logger.Log.Info("Songs fetched successfully")
 }
 
+// SearchSongs godoc
+// @Summary      Полнотекстовый поиск по текстам песен
+// @Description  Ищет песни по строке из текста или названию. Результаты упорядочены по релевантности (ts_rank), содержат фрагмент текста с подсветкой совпадений и номер совпавшего куплета (verseIndex): он открывается через GET /songs/{id}/lyrics?limit=1&page=verseIndex+1.
+// @Tags         Songs
+// @Param        q      query   string  true   "Поисковый запрос"
+// @Param        lang   query   string  false  "Язык запроса; по умолчанию определяется по алфавиту" Enums(ru, en)
+// @Param        page   query   int     false  "Номер страницы" default(1)
+// @Param        limit  query   int     false  "Количество элементов на странице" default(10)
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /songs/search [get]
+func (h *SongHandler) SearchSongs(c *gin.Context) {
+	logger.Log.Debug("Entering SearchSongs handler")
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		logger.Log.Debug("Empty search query")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	config, ok := searchConfig(c.Query("lang"), q)
+	if !ok {
+		logger.Log.WithFields(logrus.Fields{"lang": c.Query("lang")}).Debug("Invalid lang parameter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lang parameter"})
+		return
+	}
+
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"q": q, "config": config, "page": page, "limit": limit}).Info("Searching songs")
+
+	hits, err := h.repo.Search(c.Request.Context(), repository.SearchQuery{
+		Text:   q,
+		Config: config,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error searching songs in the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error searching songs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": hits, "query": q, "lang": config, "page": page, "limit": limit})
+	logger.Log.Info("Songs search completed successfully")
+}
+
+// searchConfig выбирает конфигурацию текстового поиска Postgres.
+// Без явного lang запрос с кириллицей ищется по-русски, иначе по-английски.
+func searchConfig(lang, q string) (string, bool) {
+	switch lang {
+	case "ru", "russian":
+		return "russian", true
+	case "en", "english":
+		return "english", true
+	case "":
+		for _, r := range q {
+			if unicode.Is(unicode.Cyrillic, r) {
+				return "russian", true
+			}
+		}
+		return "english", true
+	}
+	return "", false
+}
+
+// GetSong godoc
+// @Summary      Получение песни
+// @Description  Возвращает песню. Заголовок ETag — хеш её представления для If-Match при изменении.
+// @Description  Поддерживает условный GET: при совпадении If-None-Match или If-Modified-Since отвечает 304.
+// @Tags         Songs
+// @Param        id   path      int  true  "ID песни"
+// @Param        If-None-Match      header  string  false  "ETag ранее полученного ответа"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified ранее полученного ответа"
+// @Success      200  {object}  models.Song
+// @Success      304  "Песня не изменилась"
+// @Header       200  {string}  ETag  "Хеш представления песни"
+// @Header       200  {string}  Last-Modified  "Время последнего изменения песни"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/{id} [get]
+func (h *SongHandler) GetSong(c *gin.Context) {
+	logger.Log.Debug("Entering GetSong handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	song, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		respondVersionError(c, err, id, "Error fetching song")
+		return
+	}
+
+	if h.notModified(c, songETag(song), song.UpdatedAt) {
+		return
+	}
+	c.JSON(http.StatusOK, song)
+}
+
 // GetLyrics godoc
 // @Summary      Получение текста песни с пагинацией
-// @Description  Возвращает текст песни, разделённый на куплеты, с поддержкой пагинации
+// @Description  Возвращает текст песни по куплетам (строфам, разделённым пустыми строками) или по строкам. Для страницы за пределами текста возвращается 404.
 // @Tags         Songs
-// @Param        id     path     int  true   "ID песни"
-// @Param        page   query    int  false  "Номер страницы" default(1)
-// @Param        limit  query    int  false  "Количество строк на странице" default(2)
+// @Param        id     path     int     true   "ID песни"
+// @Param        unit   query    string  false  "Единица пагинации" Enums(verse, line) default(verse)
+// @Param        page   query    int     false  "Номер страницы" default(1)
+// @Param        limit  query    int     false  "Количество куплетов или строк на странице" default(2)
+// @Param        If-None-Match      header  string  false  "ETag ранее полученного ответа"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified ранее полученного ответа"
 // @Success      200    {object} map[string]interface{}
+// @Success      304    "Текст не изменился"
+// @Header       200    {string}  ETag  "Хеш представления песни"
+// @Header       200    {string}  Last-Modified  "Время последнего изменения песни"
 // @Failure      400    {object}  map[string]string
 // @Failure      404    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
 // @Router       /songs/{id}/lyrics [get]
-func GetLyrics(c *gin.Context) {
+func (h *SongHandler) GetLyrics(c *gin.Context) {
 	logger.Log.Debug("Entering GetLyrics handler")
 
-	idStr := c.Param("id")
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "2")
-
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"song_id": idStr}).Debug("Invalid song ID")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+	id, ok := parseID(c, "song")
+	if !ok {
 		return
 	}
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		logger.Log.WithFields(logrus.Fields{"page": pageStr}).Debug("Invalid page parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+	page, limit, ok := parsePagination(c, "2")
+	if !ok {
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		logger.Log.WithFields(logrus.Fields{"limit": limitStr}).Debug("Invalid limit parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+	unit := c.DefaultQuery("unit", "verse")
+	if unit != "verse" && unit != "line" {
+		logger.Log.WithFields(logrus.Fields{"unit": unit}).Debug("Invalid unit parameter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit parameter"})
 		return
 	}
 
-	var song models.Song
-	query := "SELECT * FROM songs WHERE id = $1"
-	err = database.DB.Get(&song, query, id)
-	if err != nil {
+	song, err := h.repo.Get(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrSongNotFound) {
 		logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Song not found in database")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
 		return
 	}
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching song from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching song"})
+		return
+	}
+
+	// Текст меняется только вместе с песней, поэтому куплеты можно не читать
+	if h.notModified(c, songETag(song), song.UpdatedAt) {
+		return
+	}
+
+	verses, err := h.repo.Verses(c.Request.Context(), id)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching song verses from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching song"})
+		return
+	}
+
+	var lines []string
+	for _, v := range verses {
+		lines = append(lines, v.Lines()...)
+	}
 
-	verses := strings.Split(song.Lyrics, "\n")
-	totalVerses := len(verses)
-	totalPages := int(math.Ceil(float64(totalVerses) / float64(limit)))
+	items := lines
+	if unit == "verse" {
+		items = make([]string, len(verses))
+		for i, v := range verses {
+			items[i] = v.Text
+		}
+	}
+
+	totalPages := int(math.Ceil(float64(len(items)) / float64(limit)))
+	// Первая страница пустого текста — пустой ответ, а не ошибка
+	if page > totalPages && page > 1 {
+		logger.Log.WithFields(logrus.Fields{"song_id": id, "page": page, "total_pages": totalPages}).Debug("Lyrics page out of range")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Page out of range"})
+		return
+	}
 
 	start := (page - 1) * limit
 	end := start + limit
-	if end > totalVerses {
-		end = totalVerses
+	if end > len(items) {
+		end = len(items)
 	}
 
-	logger.Log.WithFields(logrus.Fields{"song_id": id, "page": page}).Info("Fetching song lyrics with pagination")
-	c.JSON(http.StatusOK, gin.H{"lyrics": verses[start:end], "page": page, "total_pages": totalPages})
+	logger.Log.WithFields(logrus.Fields{"song_id": id, "page": page, "unit": unit}).Info("Fetching song lyrics with pagination")
+	c.JSON(http.StatusOK, gin.H{
+		"lyrics":       append([]string{}, items[start:end]...),
+		"unit":         unit,
+		"page":         page,
+		"limit":        limit,
+		"total_pages":  totalPages,
+		"total_verses": len(verses),
+		"total_lines":  len(lines),
+	})
 	logger.Log.Info("Lyrics fetched successfully")
 }
 
@@ -142,20 +427,27 @@ func GetLyrics(c *gin.Context) {
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
 // @Param        id    path      int         true  "ID песни"
+// @Param        If-Match  header  string  false  "ETag песни из GET; обязателен в строгом режиме"
 // @Param        song  body      models.Song true  "Новые данные песни"
 // @Success      200   {object}  map[string]string
+// @Header       200   {string}  ETag  "ETag изменённой песни"
 // @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      412   {object}  map[string]string
+// @Failure      428   {object}  map[string]string
+// @Failure      429   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /songs/{id} [put]
-func UpdateSong(c *gin.Context) {
+func (h *SongHandler) UpdateSong(c *gin.Context) {
 	logger.Log.Debug("Entering UpdateSong handler")
 
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"song_id": idStr}).Debug("Invalid song ID")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+	id, ok := parseID(c, "song")
+	if !ok {
 		return
 	}
 
@@ -166,62 +458,243 @@ func UpdateSong(c *gin.Context) {
 		return
 	}
 
-	query := `UPDATE songs SET group_name = $1, song_name = $2, release_date = $3, lyrics = $4, link = $5 WHERE id = $6`
-	_, err = database.DB.Exec(query, song.GroupName, song.SongName, song.ReleaseDate, song.Lyrics, song.Link, id)
-	if err != nil {
-		logger.Log.WithError(err).Debug("Failed to update song in database")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update song"})
+	version, ok := h.checkIfMatch(c, id, nil)
+	if !ok {
+		return
+	}
+
+	if !h.resolveArtist(c, &song) || !h.resolveAlbum(c, &song) {
+		return
+	}
+
+	song.ID = id
+	// Ожидаемая версия берётся только из If-Match, не из тела запроса
+	song.Version = version
+	if err := h.repo.Update(c.Request.Context(), &song); err != nil {
+		respondVersionError(c, err, id, "Failed to update song")
 		return
 	}
 
 	logger.Log.WithFields(logrus.Fields{"song_id": id}).Info("Song updated successfully")
+	// ETag строится по представлению из хранилища, как в GET
+	if updated, err := h.repo.Get(c.Request.Context(), id); err == nil {
+		setSongETag(c, updated)
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Song updated successfully"})
 }
 
 // DeleteSong godoc
-// @Summary      Удаление песни
-// @Description  Удаляет песню из библиотеки
+// @Summary      Удаление песни в корзину
+// @Description  Перемещает песню в корзину. Песни из корзины не видны в списке и поиске; их можно восстановить, пока не истёк срок хранения. В плейлистах песня остаётся с пометкой available=false.
 // @Tags         Songs
+// @Security     BearerAuth
+// @Security     APIKeyAuth
 // @Param        id   path      int  true  "ID песни"
+// @Param        If-Match  header  string  false  "ETag песни из GET; обязателен в строгом режиме"
 // @Success      200  {object}  map[string]string
 // @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      412  {object}  map[string]string
+// @Failure      428  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /songs/{id} [delete]
-func DeleteSong(c *gin.Context) {
+func (h *SongHandler) DeleteSong(c *gin.Context) {
 	logger.Log.Debug("Entering DeleteSong handler")
 
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	version, ok := h.checkIfMatch(c, id, nil)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id, version); err != nil {
+		respondVersionError(c, err, id, "Failed to delete song")
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"song_id": id}).Info("Song moved to trash")
+	c.JSON(http.StatusOK, gin.H{"message": "Song moved to trash"})
+}
+
+// GetTrash godoc
+// @Summary      Получение корзины
+// @Description  Возвращает песни из корзины с теми же фильтрами, что и список песен, и пагинацией page/limit
+// @Tags         Trash
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        group   query   string  false  "Название группы"
+// @Param        song    query   string  false  "Название песни"
+// @Param        sort    query   string  false  "Поля сортировки через запятую, минус — по убыванию"
+// @Param        page    query   int     false  "Номер страницы" default(1)
+// @Param        limit   query   int     false  "Количество элементов на странице" default(10)
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      429     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /trash [get]
+func (h *SongHandler) GetTrash(c *gin.Context) {
+	logger.Log.Debug("Entering GetTrash handler")
+
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
+
+	filter, ok := parseSongFilter(c)
+	if !ok {
+		return
+	}
+	filter.Trash = repository.OnlyTrashed
+
+	total, err := h.repo.Count(c.Request.Context(), filter)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{"song_id": idStr}).Debug("Invalid song ID")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		logger.Log.WithError(err).Debug("Error counting trashed songs in the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching trash"})
 		return
 	}
 
-	query := "DELETE FROM songs WHERE id = $1"
-	_, err = database.DB.Exec(query, id)
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+	songs, err := h.repo.List(c.Request.Context(), filter)
 	if err != nil {
-		logger.Log.WithError(err).Debug("Failed to delete song from database")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete song"})
+		logger.Log.WithError(err).Debug("Error fetching trashed songs from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching trash"})
 		return
 	}
 
-	logger.Log.WithFields(logrus.Fields{"song_id": id}).Info("Song deleted successfully")
-	c.JSON(http.StatusOK, gin.H{"message": "Song deleted successfully"})
+	totalPages := (total + limit - 1) / limit
+	setLinkHeader(c, pageLinks(c, page, totalPages))
+	c.JSON(http.StatusOK, gin.H{
+		"songs": songs, "page": page, "limit": limit, "total": total, "total_pages": totalPages,
+	})
+}
+
+// RestoreSong godoc
+// @Summary      Восстановление песни из корзины
+// @Tags         Trash
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  models.Song
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/{id}/restore [post]
+func (h *SongHandler) RestoreSong(c *gin.Context) {
+	logger.Log.Debug("Entering RestoreSong handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	err := h.repo.Restore(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrSongNotFound) {
+		logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Song not found in trash")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found in trash"})
+		return
+	}
+	if err != nil {
+		logger.Log.WithError(err).Debug("Failed to restore song from trash")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore song"})
+		return
+	}
+
+	song, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching restored song from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore song"})
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"song_id": id}).Info("Song restored from trash")
+	setSongETag(c, song)
+	c.JSON(http.StatusOK, song)
+}
+
+// PurgeSong godoc
+// @Summary      Окончательное удаление песни
+// @Description  Удаляет песню из корзины навсегда вместе с текстом и записями в плейлистах. Восстановить её будет нельзя.
+// @Tags         Trash
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /trash/{id} [delete]
+func (h *SongHandler) PurgeSong(c *gin.Context) {
+	logger.Log.Debug("Entering PurgeSong handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	err := h.repo.Purge(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrSongNotFound) {
+		logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Song not found in trash")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found in trash"})
+		return
+	}
+	if err != nil {
+		logger.Log.WithError(err).Debug("Failed to purge song from database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge song"})
+		return
+	}
+
+	logger.Log.WithFields(logrus.Fields{"song_id": id}).Info("Song purged successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Song purged successfully"})
+}
+
+// acceptedSong — ответ на добавление песни: песня сохранена, данные внешнего
+// API дополняются в фоне
+type acceptedSong struct {
+	models.Song
+	// StatusURL — адрес состояния дополнения песни
+	StatusURL string `json:"statusUrl" example:"/songs/1/enrichment"`
 }
 
 // AddSong godoc
 // @Summary      Добавление новой песни
-// @Description  Добавляет новую песню в библиотеку, запрашивая данные из внешнего API
+// @Description  Сохраняет песню сразу и ставит её в очередь на дополнение данными внешнего API: пустые дата релиза, текст, ссылка и альбом заполняются в фоне.
+// @Description  Состояние дополнения доступно по адресу из statusUrl и заголовка Location.
+// @Description  Пока автомат защиты внешнего API разомкнут, отвечает 503 с Retry-After либо, при EXTERNAL_API_SAVE_UNENRICHED=true, всё равно сохраняет песню: она будет дополнена после восстановления API.
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
 // @Param        song  body      models.Song  true  "Данные песни"
-// @Success      201   {object}  models.Song
+// @Success      202   {object}  acceptedSong
+// @Header       202   {string}  Location  "Адрес состояния дополнения"
+// @Header       202   {string}  RateLimit-Remaining  "Оставшиеся запросы в квоте"
 // @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      429   {object}  map[string]string
+// @Header       429   {string}  Retry-After  "Через сколько секунд повторить запрос"
 // @Failure      500   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Header       503   {string}  Retry-After  "Через сколько секунд автомат защиты пропустит пробный запрос"
 // @Router       /songs [post]
-func AddSong(c *gin.Context) {
+func (h *SongHandler) AddSong(c *gin.Context) {
 	var songInput models.Song
 	if err := c.ShouldBindJSON(&songInput); err != nil {
 		logger.Log.WithError(err).Debug("Invalid input data for adding a new song")
@@ -234,26 +707,156 @@ func AddSong(c *gin.Context) {
 		"song_name":  songInput.SongName,
 	}).Info("Adding a new song")
 
-	apiData, err := services.FetchExternalSong(songInput.GroupName, songInput.SongName)
-	if err != nil {
-		logger.Log.WithError(err).Debug("Failed to fetch song details from external API")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch song details: " + err.Error()})
+	if retryAfter, open := h.enricher.Unavailable(); open && !h.cfg.SaveUnenriched {
+		logger.Log.Warn("External API is unavailable, rejecting new song")
+		c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(retryAfter.Seconds())), 1)))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "External API is unavailable"})
 		return
 	}
 
-	songInput.ReleaseDate = apiData.ReleaseDate
-	songInput.Lyrics = apiData.Lyrics
-	songInput.Link = apiData.Link
+	if !h.resolveArtist(c, &songInput) || !h.resolveAlbum(c, &songInput) {
+		return
+	}
 
-	query := `INSERT INTO songs (group_name, song_name, release_date, lyrics, link) 
-	          VALUES ($1, $2, $3, $4, $5)`
-	_, err = database.DB.Exec(query, songInput.GroupName, songInput.SongName, songInput.ReleaseDate, songInput.Lyrics, songInput.Link)
+	songInput.EnrichmentStatus = models.EnrichmentPending
+	songInput.EnrichmentError = ""
+	songInput.EnrichmentAttempts = 0
+	err := h.repo.Create(c.Request.Context(), &songInput)
 	if err != nil {
 		logger.Log.WithError(err).Debug("Failed to insert song into database")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save song"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, songInput)
-	logger.Log.Info("Song added successfully")
+	if !h.enricher.Enqueue(songInput.ID) {
+		logger.Log.WithFields(logrus.Fields{"song_id": songInput.ID}).Warn("Enrichment queue is full, song will be queued by the next sweep")
+	}
+
+	statusURL := "/songs/" + strconv.Itoa(songInput.ID) + "/enrichment"
+	if created, err := h.repo.Get(c.Request.Context(), songInput.ID); err == nil {
+		songInput = *created
+		setSongETag(c, &songInput)
+	}
+	c.Header("Location", statusURL)
+	c.JSON(http.StatusAccepted, acceptedSong{Song: songInput, StatusURL: statusURL})
+	logger.Log.WithFields(logrus.Fields{"song_id": songInput.ID}).Info("Song added, enrichment queued")
+}
+
+// enrichmentResponse — состояние дополнения песни данными внешнего API
+type enrichmentResponse struct {
+	SongID    int    `json:"songId"`
+	Status    string `json:"status" enums:"pending,enriched,failed"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// GetEnrichment godoc
+// @Summary      Состояние дополнения песни
+// @Description  Возвращает состояние фонового дополнения песни данными внешнего API: pending, enriched или failed, число попыток и последнюю ошибку
+// @Tags         Songs
+// @Produce      json
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  enrichmentResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /songs/{id}/enrichment [get]
+func (h *SongHandler) GetEnrichment(c *gin.Context) {
+	logger.Log.Debug("Entering GetEnrichment handler")
+
+	id, ok := parseID(c, "song")
+	if !ok {
+		return
+	}
+
+	song, err := h.repo.Get(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrSongNotFound) {
+		logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Song not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching song from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching song"})
+		return
+	}
+	if song.EnrichmentStatus == "" {
+		logger.Log.WithFields(logrus.Fields{"song_id": id}).Debug("Song was not queued for enrichment")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song was not queued for enrichment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrichmentResponse{
+		SongID:    song.ID,
+		Status:    song.EnrichmentStatus,
+		Attempts:  song.EnrichmentAttempts,
+		LastError: song.EnrichmentError,
+	})
+}
+
+// resolveArtist находит или создаёт исполнителя по нормализованному названию
+// группы и проставляет song.ArtistID. При ошибке отвечает клиенту и возвращает false.
+func (h *SongHandler) resolveArtist(c *gin.Context, song *models.Song) bool {
+	if models.CleanName(song.GroupName) == "" {
+		logger.Log.Debug("Song group name is empty")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Group name is required"})
+		return false
+	}
+
+	artist, err := h.artists.Resolve(c.Request.Context(), song.GroupName)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Failed to resolve artist")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve artist"})
+		return false
+	}
+
+	song.ArtistID = artist.ID
+	song.GroupName = artist.Name
+	return true
+}
+
+// resolveAlbum проверяет указанный albumId или находит/создаёт альбом исполнителя
+// по названию. Дата релиза песни становится датой релиза нового альбома.
+// При ошибке отвечает клиенту и возвращает false.
+func (h *SongHandler) resolveAlbum(c *gin.Context, song *models.Song) bool {
+	if song.TrackNumber != nil && *song.TrackNumber < 1 {
+		logger.Log.WithFields(logrus.Fields{"track_number": *song.TrackNumber}).Debug("Invalid track number")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid track number"})
+		return false
+	}
+
+	var (
+		album *models.Album
+		err   error
+	)
+	switch {
+	case song.AlbumID != nil:
+		album, err = h.albums.Get(c.Request.Context(), *song.AlbumID)
+		if errors.Is(err, repository.ErrAlbumNotFound) {
+			logger.Log.WithFields(logrus.Fields{"album_id": *song.AlbumID}).Debug("Album not found in database")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Album not found"})
+			return false
+		}
+		if err == nil && album.ArtistID != song.ArtistID {
+			logger.Log.WithFields(logrus.Fields{"album_id": album.ID, "artist_id": song.ArtistID}).Debug("Album belongs to another artist")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Album belongs to another artist"})
+			return false
+		}
+	case models.CleanName(song.Album) != "":
+		album, err = h.albums.Resolve(c.Request.Context(), song.ArtistID, song.Album, song.ReleaseDate)
+	default:
+		song.Album = ""
+		song.TrackNumber = nil
+		return true
+	}
+	if err != nil {
+		logger.Log.WithError(err).Debug("Failed to resolve album")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve album"})
+		return false
+	}
+
+	song.AlbumID = &album.ID
+	song.Album = album.Title
+	return true
 }
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"music-library/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.Init()
+	logger.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// testAPI — API песен, исполнителей и альбомов поверх хранилищ в памяти,
+// без аутентификации и квот
+type testAPI struct {
+	t        *testing.T
+	router   *gin.Engine
+	songs    *repository.MemorySongRepository
+	artists  *repository.MemoryArtistRepository
+	albums   *repository.MemoryAlbumRepository
+	enricher *services.Enricher
+	external *services.ExternalClient
+}
+
+func newTestAPI(t *testing.T, cfg SongHandlerConfig) *testAPI {
+	t.Helper()
+
+	artists := repository.NewMemoryArtistRepository()
+	albums := repository.NewMemoryAlbumRepository(artists)
+	songs := repository.NewMemorySongRepository(artists, albums)
+
+	// Очередь дополнения не обрабатывается: внешний API в тестах не вызывается
+	external, err := services.NewExternalClient(services.ExternalClientConfig{
+		BaseURL:          "http://external.invalid",
+		BreakerThreshold: 1,
+		BreakerCoolDown:  time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enricher := services.NewEnricher(songs, albums, external, services.EnricherConfig{QueueSize: 100})
+
+	h := NewSongHandler(songs, artists, albums, enricher, cfg)
+	ah := NewArtistHandler(artists, songs, albums)
+	alh := NewAlbumHandler(albums, artists, songs)
+
+	r := gin.New()
+	r.GET("/songs", h.GetSongs)
+	r.GET("/songs/export", h.ExportSongs)
+	r.GET("/songs/:id", h.GetSong)
+	r.GET("/songs/:id/lyrics", h.GetLyrics)
+	r.POST("/songs", h.AddSong)
+	r.POST("/songs/import", h.ImportSongs)
+	r.PUT("/songs/:id", h.UpdateSong)
+	r.PATCH("/songs/:id", h.PatchSong)
+	r.DELETE("/songs/:id", h.DeleteSong)
+	r.POST("/songs/:id/restore", h.RestoreSong)
+	r.GET("/trash", h.GetTrash)
+	r.DELETE("/trash/:id", h.PurgeSong)
+	r.GET("/songs/:id/revisions", h.GetRevisions)
+	r.GET("/songs/:id/revisions/diff", h.DiffRevisions)
+	r.GET("/songs/:id/revisions/:rev", h.GetRevision)
+	r.POST("/songs/:id/revisions/:rev/revert", h.RevertSong)
+	r.PUT("/artists/:id", ah.UpdateArtist)
+	r.PUT("/albums/:id", alh.UpdateAlbum)
+
+	return &testAPI{t: t, router: r, songs: songs, artists: artists, albums: albums, enricher: enricher, external: external}
+}
+
+// do выполняет запрос; body кодируется в JSON, если это не []byte
+func (a *testAPI) do(method, target string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+// addSong создаёт песню через POST /songs и возвращает её
+func (a *testAPI) addSong(song map[string]interface{}) models.Song {
+	a.t.Helper()
+
+	w := a.do(http.MethodPost, "/songs", song)
+	if w.Code != http.StatusAccepted {
+		a.t.Fatalf("POST /songs: status %d, body %s", w.Code, w.Body)
+	}
+	var created models.Song
+	decode(a.t, w, &created)
+	return created
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+}
+
+func songPath(id int) string {
+	return "/songs/" + strconv.Itoa(id)
+}
+
+func TestAddAndGetSong(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	created := api.addSong(map[string]interface{}{
+		"group":  "  Muse ",
+		"song":   "Supermassive Black Hole",
+		"album":  "Black Holes and Revelations",
+		"lyrics": "Ooh baby\n\nSecond verse",
+	})
+	if created.ID == 0 || created.ArtistID == 0 || created.AlbumID == nil {
+		t.Fatalf("created song is missing IDs: %+v", created)
+	}
+	if created.EnrichmentStatus != models.EnrichmentPending {
+		t.Errorf("enrichment status = %q, want pending", created.EnrichmentStatus)
+	}
+
+	w := api.do(http.MethodGet, songPath(created.ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: status %d, body %s", w.Code, w.Body)
+	}
+	var got models.Song
+	decode(t, w, &got)
+	if got.GroupName != "Muse" || got.Album != "Black Holes and Revelations" {
+		t.Errorf("got group %q, album %q", got.GroupName, got.Album)
+	}
+	if w.Header().Get("ETag") == "" {
+		t.Error("GET response has no ETag")
+	}
+}
+
+func TestAddSongValidation(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{"malformed JSON", []byte(`{"group":`)},
+		{"missing group", map[string]interface{}{"song": "Song"}},
+		{"invalid track number", map[string]interface{}{"group": "Muse", "song": "Song", "album": "Album", "trackNumber": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := api.do(http.MethodPost, "/songs", tt.body); w.Code != http.StatusBadRequest {
+				t.Errorf("status %d, want 400; body %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestAddSongWhileExternalAPIUnavailable(t *testing.T) {
+	song := map[string]interface{}{"group": "Muse", "song": "Uprising"}
+
+	api := newTestAPI(t, SongHandlerConfig{})
+	api.external.Breaker().Done(true)
+	w := api.do(http.MethodPost, "/songs", song)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503, body %s", w.Code, w.Body)
+	}
+	if retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After = %q", w.Header().Get("Retry-After"))
+	}
+	if n, _ := api.songs.Count(context.Background(), repository.SongFilter{}); n != 0 {
+		t.Errorf("%d songs saved while failing fast", n)
+	}
+
+	api = newTestAPI(t, SongHandlerConfig{SaveUnenriched: true})
+	api.external.Breaker().Done(true)
+	if created := api.addSong(song); created.EnrichmentStatus != models.EnrichmentPending {
+		t.Errorf("enrichment status = %q, want pending", created.EnrichmentStatus)
+	}
+}
+
+func TestGetSongErrors(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+
+	if w := api.do(http.MethodGet, "/songs/abc", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid ID: status %d, want 400", w.Code)
+	}
+	if w := api.do(http.MethodGet, "/songs/42", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown ID: status %d, want 404", w.Code)
+	}
+}
+
+func TestListSongsFiltersAndPaginates(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	for _, s := range []string{"Uprising", "Hysteria", "Madness"} {
+		api.addSong(map[string]interface{}{"group": "Muse", "song": s})
+	}
+	api.addSong(map[string]interface{}{"group": "Radiohead", "song": "Creep"})
+
+	w := api.do(http.MethodGet, "/songs?group=Muse&limit=2&sort=song", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Songs      []models.Song `json:"songs"`
+		Total      int           `json:"total"`
+		TotalPages int           `json:"total_pages"`
+	}
+	decode(t, w, &resp)
+	if resp.Total != 3 || resp.TotalPages != 2 {
+		t.Errorf("total = %d, total_pages = %d, want 3 and 2", resp.Total, resp.TotalPages)
+	}
+	if len(resp.Songs) != 2 || resp.Songs[0].SongName != "Hysteria" || resp.Songs[1].SongName != "Madness" {
+		t.Errorf("unexpected first page: %+v", resp.Songs)
+	}
+	if w.Header().Get("Link") == "" {
+		t.Error("list response has no Link header")
+	}
+
+	if w := api.do(http.MethodGet, "/songs?page=0", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("page=0: status %d, want 400", w.Code)
+	}
+}
+
+func TestUpdateAndDeleteSong(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+
+	w := api.do(http.MethodPut, songPath(created.ID), map[string]interface{}{
+		"group": "Muse", "song": "Uprising", "releaseDate": "2009-09-07",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: status %d, body %s", w.Code, w.Body)
+	}
+	got, err := api.songs.Get(context.Background(), created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ReleaseDate != "2009-09-07" || got.Version <= created.Version {
+		t.Errorf("after PUT: release date %q, version %d (was %d)", got.ReleaseDate, got.Version, created.Version)
+	}
+
+	if w := api.do(http.MethodDelete, songPath(created.ID), nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE: status %d, body %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, songPath(created.ID), nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE: status %d, want 404", w.Code)
+	}
+	if w := api.do(http.MethodPost, songPath(created.ID)+"/restore", nil); w.Code != http.StatusOK {
+		t.Errorf("restore: status %d, body %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, songPath(created.ID), nil); w.Code != http.StatusOK {
+		t.Errorf("GET after restore: status %d, want 200", w.Code)
+	}
+}
+
+func TestGetLyricsPaginatesVerses(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{
+		"group": "Muse", "song": "Uprising",
+		"lyrics": "first line\nsecond line\n\nthird line\n\nfourth line",
+	})
+
+	w := api.do(http.MethodGet, songPath(created.ID)+"/lyrics?limit=2&page=2", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Lyrics      []string `json:"lyrics"`
+		TotalVerses int      `json:"total_verses"`
+		TotalLines  int      `json:"total_lines"`
+	}
+	decode(t, w, &resp)
+	if len(resp.Lyrics) != 1 || resp.Lyrics[0] != "fourth line" {
+		t.Errorf("page 2 = %q, want the third verse", resp.Lyrics)
+	}
+	if resp.TotalVerses != 3 || resp.TotalLines != 4 {
+		t.Errorf("totals = %d verses, %d lines", resp.TotalVerses, resp.TotalLines)
+	}
+
+	if w := api.do(http.MethodGet, songPath(created.ID)+"/lyrics?limit=2&page=3", nil); w.Code != http.StatusNotFound {
+		t.Errorf("page out of range: status %d, want 404", w.Code)
+	}
+}
+
+func TestGetLyricsUnits(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	created := api.addSong(map[string]interface{}{
+		"group": "Muse", "song": "Uprising",
+		"lyrics": "first line\r\nsecond line  \r\n\r\n\r\nthird line\n\nfourth line\nfifth line\n",
+	})
+	empty := api.addSong(map[string]interface{}{"group": "Muse", "song": "Instrumental"})
+
+	tests := []struct {
+		name       string
+		id         int
+		query      string
+		status     int
+		want       []string
+		totalPages int
+	}{
+		{"verses by default", created.ID, "", http.StatusOK, []string{"first line\nsecond line", "third line"}, 2},
+		{"last verse page", created.ID, "unit=verse&page=2", http.StatusOK, []string{"fourth line\nfifth line"}, 2},
+		{"verse page out of range", created.ID, "unit=verse&page=3", http.StatusNotFound, nil, 0},
+		{"lines", created.ID, "unit=line&limit=2", http.StatusOK, []string{"first line", "second line"}, 3},
+		{"lines across verses", created.ID, "unit=line&limit=2&page=2", http.StatusOK, []string{"third line", "fourth line"}, 3},
+		{"last line page", created.ID, "unit=line&limit=2&page=3", http.StatusOK, []string{"fifth line"}, 3},
+		{"line page out of range", created.ID, "unit=line&limit=2&page=4", http.StatusNotFound, nil, 0},
+		{"all lines on one page", created.ID, "unit=line&limit=10", http.StatusOK, []string{"first line", "second line", "third line", "fourth line", "fifth line"}, 1},
+		{"empty lyrics", empty.ID, "", http.StatusOK, []string{}, 0},
+		{"empty lyrics page out of range", empty.ID, "page=2", http.StatusNotFound, nil, 0},
+		{"unknown unit", created.ID, "unit=word", http.StatusBadRequest, nil, 0},
+		{"invalid page", created.ID, "page=0", http.StatusBadRequest, nil, 0},
+		{"unknown song", 999, "", http.StatusNotFound, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := api.do(http.MethodGet, songPath(tt.id)+"/lyrics?"+tt.query, nil)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Lyrics      []string `json:"lyrics"`
+				TotalPages  int      `json:"total_pages"`
+				TotalVerses int      `json:"total_verses"`
+				TotalLines  int      `json:"total_lines"`
+			}
+			decode(t, w, &resp)
+			if !reflect.DeepEqual(resp.Lyrics, tt.want) {
+				t.Errorf("lyrics = %q, want %q", resp.Lyrics, tt.want)
+			}
+			if resp.TotalPages != tt.totalPages {
+				t.Errorf("total_pages = %d, want %d", resp.TotalPages, tt.totalPages)
+			}
+			if tt.id == created.ID && (resp.TotalVerses != 3 || resp.TotalLines != 5) {
+				t.Errorf("totals = %d verses, %d lines", resp.TotalVerses, resp.TotalLines)
+			}
+		})
+	}
+}
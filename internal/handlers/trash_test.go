@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+type songList struct {
+	Songs []models.Song `json:"songs"`
+	Total int           `json:"total"`
+}
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	api := newTestAPI(t, SongHandlerConfig{})
+	kept := api.addSong(map[string]interface{}{"group": "Muse", "song": "Uprising"})
+	trashed := api.addSong(map[string]interface{}{"group": "Muse", "song": "Hysteria", "lyrics": "It's bugging me"})
+
+	if w := api.do(http.MethodDelete, songPath(trashed.ID), nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE: status %d, body %s", w.Code, w.Body)
+	}
+
+	// Песня в корзине не видна ни в списке, ни по ID, ни в тексте
+	var list songList
+	decode(t, api.do(http.MethodGet, "/songs", nil), &list)
+	if list.Total != 1 || len(list.Songs) != 1 || list.Songs[0].ID != kept.ID {
+		t.Errorf("list = %+v", list)
+	}
+	for _, target := range []string{songPath(trashed.ID), songPath(trashed.ID) + "/lyrics"} {
+		if w := api.do(http.MethodGet, target, nil); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want 404", target, w.Code)
+		}
+	}
+	if w := api.do(http.MethodDelete, songPath(trashed.ID), nil); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE trashed song again: status %d, want 404", w.Code)
+	}
+
+	decode(t, api.do(http.MethodGet, "/trash", nil), &list)
+	if list.Total != 1 || len(list.Songs) != 1 || list.Songs[0].ID != trashed.ID || list.Songs[0].DeletedAt == nil {
+		t.Fatalf("trash = %+v", list)
+	}
+
+	// Восстановление возвращает песню, в корзину её можно отправить снова
+	w := api.do(http.MethodPost, songPath(trashed.ID)+"/restore", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore: status %d, body %s", w.Code, w.Body)
+	}
+	var restored models.Song
+	decode(t, w, &restored)
+	if restored.DeletedAt != nil || restored.Lyrics != "It's bugging me" {
+		t.Errorf("restored song = %+v", restored)
+	}
+	if w := api.do(http.MethodPost, songPath(trashed.ID)+"/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("restore a song outside trash: status %d, want 404", w.Code)
+	}
+
+	// Окончательно удалить можно только песню из корзины
+	trashPath := "/trash/" + strconv.Itoa(trashed.ID)
+	if w := api.do(http.MethodDelete, trashPath, nil); w.Code != http.StatusNotFound {
+		t.Errorf("purge a song outside trash: status %d, want 404", w.Code)
+	}
+	api.do(http.MethodDelete, songPath(trashed.ID), nil)
+	if w := api.do(http.MethodDelete, trashPath, nil); w.Code != http.StatusOK {
+		t.Fatalf("purge: status %d, body %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodPost, songPath(trashed.ID)+"/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("restore a purged song: status %d, want 404", w.Code)
+	}
+	decode(t, api.do(http.MethodGet, "/trash", nil), &list)
+	if list.Total != 0 {
+		t.Errorf("trash after purge = %+v", list)
+	}
+}
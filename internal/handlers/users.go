@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"music-library/internal/auth"
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// UserHandler обслуживает эндпоинты /users для администраторов
+type UserHandler struct {
+	repo repository.UserRepository
+}
+
+func NewUserHandler(repo repository.UserRepository) *UserHandler {
+	return &UserHandler{repo: repo}
+}
+
+// roleInput — тело запроса на смену роли
+type roleInput struct {
+	Role string `json:"role" binding:"required" enums:"reader,editor,moderator,admin"`
+}
+
+// GetUsers godoc
+// @Summary      Получение списка пользователей
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        page   query   int  false  "Номер страницы" default(1)
+// @Param        limit  query   int  false  "Количество элементов на странице" default(10)
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /users [get]
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	logger.Log.Debug("Entering GetUsers handler")
+
+	page, limit, ok := parsePagination(c, "10")
+	if !ok {
+		return
+	}
+
+	users, err := h.repo.List(c.Request.Context(), limit, (page-1)*limit)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching users from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users, "page": page, "limit": limit})
+}
+
+// GetUser godoc
+// @Summary      Получение пользователя
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID пользователя"
+// @Success      200  {object}  models.User
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	logger.Log.Debug("Entering GetUser handler")
+
+	id, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	user, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, id, "Error fetching user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// SetUserRole godoc
+// @Summary      Смена роли пользователя
+// @Description  Назначает роль reader, editor, moderator или admin. Смена записывается в журнал.
+// @Description  Последнего администратора разжаловать нельзя.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id    path      int        true  "ID пользователя"
+// @Param        role  body      roleInput  true  "Новая роль"
+// @Success      200   {object}  models.User
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      429   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /users/{id}/role [put]
+func (h *UserHandler) SetUserRole(c *gin.Context) {
+	logger.Log.Debug("Entering SetUserRole handler")
+
+	id, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	var input roleInput
+	if err := c.ShouldBindJSON(&input); err != nil || !auth.ValidRole(input.Role) {
+		logger.Log.WithError(err).Debug("Invalid input data for user role")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role", "allowed": auth.Roles})
+		return
+	}
+
+	principal, _ := auth.PrincipalFrom(c)
+	actor := &models.User{ID: principal.UserID, Username: principal.Username}
+	change, err := h.repo.SetRole(c.Request.Context(), id, input.Role, actor)
+	if err != nil {
+		h.respondError(c, err, id, "Failed to change user role")
+		return
+	}
+
+	if change != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"audit":      "role_change",
+			"user_id":    change.UserID,
+			"username":   change.Username,
+			"old_role":   change.OldRole,
+			"new_role":   change.NewRole,
+			"changed_by": change.ChangedByUsername,
+		}).Warn("User role changed")
+	}
+
+	user, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, id, "Error fetching user")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// GetRoleChanges godoc
+// @Summary      Журнал смены ролей
+// @Description  Возвращает смены роли пользователя по времени, включая смены для удалённых пользователей
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID пользователя"
+// @Success      200  {array}   models.RoleChange
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /users/{id}/role-changes [get]
+func (h *UserHandler) GetRoleChanges(c *gin.Context) {
+	logger.Log.Debug("Entering GetRoleChanges handler")
+
+	id, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	changes, err := h.repo.RoleChanges(c.Request.Context(), id)
+	if err != nil {
+		logger.Log.WithError(err).Debug("Error fetching role changes from the database")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching role changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// DeleteUser godoc
+// @Summary      Удаление пользователя
+// @Description  Удаляет пользователя вместе с его ключами API. Последнего администратора удалить нельзя.
+// @Tags         Users
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        id   path      int  true  "ID пользователя"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	logger.Log.Debug("Entering DeleteUser handler")
+
+	id, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, id, "Failed to delete user")
+		return
+	}
+
+	principal, _ := auth.PrincipalFrom(c)
+	logger.Log.WithFields(logrus.Fields{"user_id": id, "deleted_by": principal.Username}).Info("User deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// respondError переводит ошибки UserRepository в HTTP-ответы.
+// Для неизвестных ошибок отвечает 500 с сообщением fallback.
+func (h *UserHandler) respondError(c *gin.Context, err error, id int, fallback string) {
+	fields := logrus.Fields{"user_id": id}
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		logger.Log.WithFields(fields).Debug("User not found in database")
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, repository.ErrLastAdmin):
+		logger.Log.WithFields(fields).Debug("Refused to remove the last admin")
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last admin"})
+	default:
+		logger.Log.WithError(err).WithFields(fields).Debug(fallback)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
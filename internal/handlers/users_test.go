@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"music-library/internal/auth"
+	"music-library/internal/middleware"
+	"music-library/internal/models"
+)
+
+// addSongAs создаёт песню от имени владельца token
+func (a *authAPI) addSongAs(token string, song map[string]string) models.Song {
+	a.t.Helper()
+
+	w := a.do(http.MethodPost, "/songs", song, bearer(token)...)
+	if w.Code != http.StatusAccepted {
+		a.t.Fatalf("POST /songs: status %d, body %s", w.Code, w.Body)
+	}
+	var created models.Song
+	decode(a.t, w, &created)
+	return created
+}
+
+func userPath(id int) string {
+	return "/users/" + strconv.Itoa(id)
+}
+
+func TestAuthorizeDeniesByRole(t *testing.T) {
+	api := newAuthAPI(t)
+	_, admin := api.user("root", auth.RoleAdmin)
+	_, reader := api.user("reader", auth.RoleReader)
+	_, editor := api.user("editor", auth.RoleEditor)
+	_, moderator := api.user("moderator", auth.RoleModerator)
+
+	song := map[string]string{"group": "Muse", "song": "Uprising"}
+	created := api.addSongAs(admin, song)
+
+	tests := []struct {
+		name, token, method, target string
+		body                        interface{}
+		permission                  auth.Permission
+	}{
+		{"reader writes", reader, http.MethodPost, "/songs", song, auth.PermSongsWrite},
+		{"editor deletes", editor, http.MethodDelete, songPath(created.ID), nil, auth.PermSongsDelete},
+		{"moderator manages users", moderator, http.MethodGet, "/users", nil, auth.PermUsersManage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := api.do(tt.method, tt.target, tt.body, bearer(tt.token)...)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status %d, want 403; body %s", w.Code, w.Body)
+			}
+			var denied map[string]string
+			decode(t, w, &denied)
+			if denied["reason"] != middleware.ReasonInsufficientRole || denied["permission"] != string(tt.permission) {
+				t.Errorf("403 body = %v", denied)
+			}
+		})
+	}
+
+	if w := api.do(http.MethodPost, "/songs", song, bearer(editor)...); w.Code != http.StatusAccepted {
+		t.Errorf("editor write: status %d", w.Code)
+	}
+	if w := api.do(http.MethodDelete, songPath(created.ID), nil, bearer(moderator)...); w.Code != http.StatusOK {
+		t.Errorf("moderator delete: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+// Смена роли действует сразу: роль читается при каждом запросе, а не из токена
+func TestSetUserRole(t *testing.T) {
+	api := newAuthAPI(t)
+	root, admin := api.user("root", auth.RoleAdmin)
+	user, token := api.user("alice", auth.RoleReader)
+	song := map[string]string{"group": "Muse", "song": "Uprising"}
+
+	if w := api.do(http.MethodPost, "/songs", song, bearer(token)...); w.Code != http.StatusForbidden {
+		t.Fatalf("reader write: status %d", w.Code)
+	}
+	w := api.do(http.MethodPut, userPath(user.ID)+"/role", map[string]string{"role": auth.RoleEditor}, bearer(admin)...)
+	if w.Code != http.StatusOK {
+		t.Fatalf("set role: status %d, body %s", w.Code, w.Body)
+	}
+	var updated models.User
+	decode(t, w, &updated)
+	if updated.Role != auth.RoleEditor {
+		t.Errorf("role = %q, want editor", updated.Role)
+	}
+	if w := api.do(http.MethodPost, "/songs", song, bearer(token)...); w.Code != http.StatusAccepted {
+		t.Errorf("write after promotion: status %d", w.Code)
+	}
+
+	// Смена записывается в журнал, повторное назначение той же роли — нет
+	api.do(http.MethodPut, userPath(user.ID)+"/role", map[string]string{"role": auth.RoleEditor}, bearer(admin)...)
+	w = api.do(http.MethodGet, userPath(user.ID)+"/role-changes", nil, bearer(admin)...)
+	var changes []models.RoleChange
+	decode(t, w, &changes)
+	if len(changes) != 1 {
+		t.Fatalf("role changes = %+v, want one", changes)
+	}
+	ch := changes[0]
+	if ch.OldRole != auth.RoleReader || ch.NewRole != auth.RoleEditor || ch.ChangedByID != root.ID || ch.ChangedByUsername != "root" || ch.Username != "alice" {
+		t.Errorf("role change = %+v", ch)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		target string
+		role   string
+		status int
+	}{
+		{"unknown role", userPath(user.ID) + "/role", "owner", http.StatusBadRequest},
+		{"unknown user", userPath(999) + "/role", auth.RoleEditor, http.StatusNotFound},
+	} {
+		if w := api.do(http.MethodPut, tt.target, map[string]string{"role": tt.role}, bearer(admin)...); w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
+
+func TestLastAdminIsProtected(t *testing.T) {
+	api := newAuthAPI(t)
+	root, admin := api.user("root", auth.RoleAdmin)
+
+	if w := api.do(http.MethodPut, userPath(root.ID)+"/role", map[string]string{"role": auth.RoleReader}, bearer(admin)...); w.Code != http.StatusConflict {
+		t.Errorf("demote last admin: status %d, want 409", w.Code)
+	}
+	if w := api.do(http.MethodDelete, userPath(root.ID), nil, bearer(admin)...); w.Code != http.StatusConflict {
+		t.Errorf("delete last admin: status %d, want 409", w.Code)
+	}
+
+	// Со вторым админом первого можно разжаловать
+	second, _ := api.user("second", auth.RoleAdmin)
+	if w := api.do(http.MethodPut, userPath(root.ID)+"/role", map[string]string{"role": auth.RoleReader}, bearer(admin)...); w.Code != http.StatusOK {
+		t.Fatalf("demote with another admin: status %d, body %s", w.Code, w.Body)
+	}
+	// Разжалованный админ теряет права сразу
+	if w := api.do(http.MethodDelete, userPath(second.ID), nil, bearer(admin)...); w.Code != http.StatusForbidden {
+		t.Errorf("demoted admin deletes a user: status %d, want 403", w.Code)
+	}
+}
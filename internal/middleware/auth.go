@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"music-library/internal/auth"
+	"music-library/internal/logger"
+	"music-library/internal/ratelimit"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Причины отказа в доступе в поле reason ответа 403
+const (
+	ReasonInsufficientRole  = "insufficient_role"
+	ReasonInsufficientScope = "insufficient_scope"
+)
+
+// lastUsedPrecision — время последнего использования ключа API обновляется
+// не чаще, чем раз в этот интервал, чтобы не писать в базу на каждый запрос
+const lastUsedPrecision = time.Minute
+
+// Authenticate определяет пользователя запроса по access-токену
+// (Authorization: Bearer <jwt>) или ключу API (Authorization: Bearer <key>
+// либо X-API-Key). Пользователь сохраняется в gin.Context (auth.PrincipalFrom)
+// и становится автором изменений в истории правок. Роль читается из хранилища
+// при каждом запросе, чтобы её смена действовала сразу. Запрос без учётных
+// данных проходит анонимно, недействительные учётные данные отклоняются с 401.
+//
+// Каждая неудачная проверка списывает токен из ведра IP-адреса клиента
+// в failures. Когда ведро пусто, учётные данные с этого адреса не проверяются
+// и запрос получает 429: подбор ключей и токенов не доходит до хранилища.
+func Authenticate(tokens *auth.TokenService, keys repository.APIKeyRepository, users repository.UserRepository, failures *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential := strings.TrimSpace(c.GetHeader("X-API-Key"))
+		if header := c.GetHeader("Authorization"); header != "" && credential == "" {
+			scheme, token, _ := strings.Cut(header, " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				unauthorized(c, "Invalid authorization header")
+				return
+			}
+			credential = strings.TrimSpace(token)
+		}
+		if credential == "" {
+			c.Next()
+			return
+		}
+		client := "ip:" + c.ClientIP()
+		if res := failures.Check(client, time.Now()); !res.Allowed {
+			tooManyRequests(c, "auth", client, res)
+			return
+		}
+
+		var principal *auth.Principal
+		if auth.IsAPIKey(credential) {
+			principal = authenticateKey(c, keys, credential)
+		} else if claims, err := tokens.Parse(credential, auth.AccessToken); err == nil {
+			principal = authenticateUser(c, users, claims.UserID())
+		}
+		if c.IsAborted() {
+			return
+		}
+		if principal == nil {
+			failures.Allow(client, time.Now())
+			logger.Log.WithFields(logrus.Fields{"path": c.Request.URL.Path}).Debug("Rejected invalid credentials")
+			unauthorized(c, "Invalid or expired credentials")
+			return
+		}
+
+		auth.SetPrincipal(c, principal)
+		c.Request = c.Request.WithContext(repository.WithAuthor(c.Request.Context(), principal.Username))
+		c.Next()
+	}
+}
+
+// authenticateUser загружает владельца access-токена.
+// Возвращает nil, если пользователь удалён.
+func authenticateUser(c *gin.Context, users repository.UserRepository, id int) *auth.Principal {
+	user, err := users.Get(c.Request.Context(), id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			logger.Log.WithError(err).Error("Failed to load authenticated user")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		}
+		return nil
+	}
+	return &auth.Principal{UserID: user.ID, Username: user.Username, Role: user.Role}
+}
+
+// authenticateKey проверяет ключ API и отмечает его использование.
+// Возвращает nil для неизвестного, отозванного или истёкшего ключа.
+func authenticateKey(c *gin.Context, keys repository.APIKeyRepository, key string) *auth.Principal {
+	ctx := c.Request.Context()
+	apiKey, err := keys.Lookup(ctx, auth.HashAPIKey(key))
+	if err != nil {
+		if !errors.Is(err, repository.ErrAPIKeyNotFound) {
+			logger.Log.WithError(err).Error("Failed to look up API key")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		}
+		return nil
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedPrecision {
+		if err := keys.Touch(ctx, apiKey.ID, now); err != nil {
+			logger.Log.WithError(err).WithFields(logrus.Fields{"api_key_id": apiKey.ID}).Warn("Failed to record API key usage")
+		}
+	}
+	return &auth.Principal{
+		UserID:   apiKey.UserID,
+		Username: apiKey.Username,
+		Role:     apiKey.Role,
+		APIKeyID: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}
+}
+
+// RequireUser пропускает только аутентифицированные запросы.
+// Ставится на маршруты после Authenticate.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.PrincipalFrom(c); !ok {
+			unauthorized(c, "Authentication required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// Authorize пропускает запрос, если действие perm разрешено его роли,
+// а для ключа API — ещё и областям доступа ключа. Анонимным запросам
+// доступны только разрешения из auth.AnonymousAllows, остальные получают 401.
+// Отказ отвечает 403 с машиночитаемой причиной в поле reason.
+func Authorize(perm auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFrom(c)
+		switch {
+		case !ok && auth.AnonymousAllows(perm):
+		case !ok:
+			unauthorized(c, "Authentication required")
+			return
+		case !auth.RoleAllows(principal.Role, perm):
+			logger.Log.WithFields(logrus.Fields{
+				"user_id":    principal.UserID,
+				"role":       principal.Role,
+				"permission": perm,
+				"path":       c.Request.URL.Path,
+			}).Info("Permission denied by role")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "Insufficient role",
+				"reason":     ReasonInsufficientRole,
+				"permission": perm,
+				"role":       principal.Role,
+			})
+			return
+		case !principal.HasScope(auth.ScopeFor(perm)):
+			logger.Log.WithFields(logrus.Fields{
+				"api_key_id": principal.APIKeyID,
+				"permission": perm,
+				"path":       c.Request.URL.Path,
+			}).Info("Permission denied by API key scope")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "API key lacks required scope",
+				"reason":     ReasonInsufficientScope,
+				"permission": perm,
+				"scope":      auth.ScopeFor(perm),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="music-library"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"music-library/internal/ratelimit"
+	"music-library/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingKeys считает обращения к хранилищу ключей
+type countingKeys struct {
+	*repository.MemoryAPIKeyRepository
+	lookups atomic.Int32
+}
+
+func (k *countingKeys) Lookup(ctx context.Context, hash string) (*models.APIKey, error) {
+	k.lookups.Add(1)
+	return k.MemoryAPIKeyRepository.Lookup(ctx, hash)
+}
+
+// Неудачные проверки расходуют квоту IP, после чего учётные данные
+// не проверяются вовсе
+func TestAuthenticateLimitsFailedAttempts(t *testing.T) {
+	users := repository.NewMemoryUserRepository()
+	keys := &countingKeys{MemoryAPIKeyRepository: repository.NewMemoryAPIKeyRepository(users)}
+	tokens := auth.NewTokenService([]byte("0123456789abcdef0123456789abcdef"), time.Minute, time.Hour)
+
+	user := &models.User{Username: "alice", PasswordHash: "x"}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tokens.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(Authenticate(tokens, keys, users, ratelimit.New(ratelimit.Limit{Requests: 2, Period: time.Minute})))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	get := func(ip, credential string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, credential := range []string{"ml_bogus1", "not-a-jwt"} {
+		if w := get("10.0.0.1", credential); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status %d, want 401", i, w.Code)
+		}
+	}
+	w := get("10.0.0.1", "ml_bogus2")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("after quota: status %d, headers %v", w.Code, w.Header())
+	}
+	if n := keys.lookups.Load(); n != 1 {
+		t.Errorf("lookups = %d, want 1: limited requests must not reach the store", n)
+	}
+
+	// Действующий токен с того же адреса тоже ждёт, другие адреса не затронуты
+	if w := get("10.0.0.1", pair.AccessToken); w.Code != http.StatusTooManyRequests {
+		t.Errorf("valid token from a blocked IP: status %d", w.Code)
+	}
+	if w := get("10.0.0.2", pair.AccessToken); w.Code != http.StatusNoContent {
+		t.Errorf("valid token from another IP: status %d", w.Code)
+	}
+	// Анонимные запросы квотой неудач не ограничиваются
+	if w := get("10.0.0.1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("anonymous request: status %d", w.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"music-library/internal/auth"
+	"music-library/internal/logger"
+	"music-library/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimit ограничивает частоту запросов квотой limiter. Квота считается
+// отдельно для каждого ключа API, пользователя или, для анонимных запросов,
+// IP-адреса, поэтому middleware ставится после Authenticate. Ответ несёт
+// заголовки RateLimit-*, превышение квоты отвечает 429 с Retry-After.
+// nil-ограничитель ничего не ограничивает.
+func RateLimit(group string, limiter *ratelimit.Limiter) gin.HandlerFunc {
+	if limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limit := limiter.Limit()
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(ceilSeconds(limit.Period))
+
+	return func(c *gin.Context) {
+		client := rateLimitKey(c)
+		res := limiter.Allow(client, time.Now())
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			tooManyRequests(c, group, client, res)
+			return
+		}
+		c.Next()
+	}
+}
+
+// tooManyRequests отклоняет запрос сверх квоты с 429 и Retry-After
+func tooManyRequests(c *gin.Context, group, client string, res ratelimit.Result) {
+	retryAfter := ceilSeconds(res.RetryAfter)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	logger.Log.WithFields(logrus.Fields{
+		"group":  group,
+		"client": client,
+		"path":   c.Request.URL.Path,
+	}).Info("Rate limit exceeded")
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Rate limit exceeded",
+		"retryAfter": retryAfter,
+	})
+}
+
+// rateLimitKey выбирает, чью квоту расходует запрос
+func rateLimitKey(c *gin.Context) string {
+	if p, ok := auth.PrincipalFrom(c); ok {
+		if p.APIKeyID != 0 {
+			return "key:" + strconv.Itoa(p.APIKeyID)
+		}
+		return "user:" + strconv.Itoa(p.UserID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд, как того
+// требуют заголовки Retry-After и RateLimit-Reset
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.Init()
+	logger.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestRateLimit(t *testing.T) {
+	r := gin.New()
+	r.GET("/", RateLimit("read", ratelimit.New(ratelimit.Limit{Requests: 2, Period: time.Minute})), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	get := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("10.0.0.1")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", w.Code)
+	}
+	if w.Header().Get("RateLimit-Policy") != "2;w=60" || w.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("headers = %v", w.Header())
+	}
+
+	get("10.0.0.1")
+	w = get("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: status %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" {
+		t.Errorf("Retry-After = %q, want 30", w.Header().Get("Retry-After"))
+	}
+
+	// Квота анонимных клиентов считается по IP
+	if w := get("10.0.0.2"); w.Code != http.StatusNoContent {
+		t.Errorf("another client: status %d", w.Code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	r := gin.New()
+	r.GET("/", RateLimit("read", nil), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("request %d: status %d, headers %v", i, w.Code, w.Header())
+		}
+	}
+}
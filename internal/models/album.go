@@ -0,0 +1,12 @@
+package models
+
+type Album struct {
+	ID              int    `json:"id" db:"id"`
+	ArtistID        int    `json:"artistId" db:"artist_id"`
+	Artist          string `json:"artist" db:"artist_name"`
+	Title           string `json:"title" db:"title"`
+	NormalizedTitle string `json:"-" db:"normalized_title"`
+	ReleaseDate     string `json:"releaseDate" db:"release_date"`
+	// Tracks заполняется только при запросе одного альбома
+	Tracks []Song `json:"tracks,omitempty" db:"-"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// APIKey — долгоживущий ключ доступа для скриптов и сервисов.
+// Ключ действует от имени владельца, но только в пределах Scopes.
+type APIKey struct {
+	ID       int      `json:"id" db:"id"`
+	UserID   int      `json:"userId" db:"user_id"`
+	Username string   `json:"-" db:"username"`
+	Role     string   `json:"-" db:"role"`
+	Name     string   `json:"name" db:"name"`
+	Prefix   string   `json:"prefix" db:"prefix"`
+	Hash     string   `json:"-" db:"key_hash"`
+	Scopes   []string `json:"scopes" db:"-"`
+	// ExpiresAt равно nil для бессрочного ключа
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" db:"expires_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+}
+
+// Active сообщает, можно ли пользоваться ключом в момент now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
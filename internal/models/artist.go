@@ -0,0 +1,7 @@
+package models
+
+type Artist struct {
+	ID             int    `json:"id" db:"id"`
+	Name           string `json:"name" db:"name"`
+	NormalizedName string `json:"-" db:"normalized_name"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "errors"
+
+// Операции построчного сравнения
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// MaxDiffCells ограничивает произведение числа строк, которые различаются
+// в двух текстах после отбрасывания общих начала и конца. Таблица НОП
+// такого размера занимает 16 МБ.
+const MaxDiffCells = 1 << 22
+
+// ErrDiffTooLarge возвращается, когда тексты различаются слишком сильно,
+// чтобы сравнить их за разумные время и память
+var ErrDiffTooLarge = errors.New("texts are too large to compare")
+
+// DiffLine — строка результата сравнения двух текстов
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines строит построчный diff от a к b по наибольшей общей
+// подпоследовательности. Удаления выводятся перед вставками. Общие начало
+// и конец текстов в сравнении не участвуют, поэтому небольшая правка
+// длинного текста сравнивается быстро.
+func DiffLines(a, b []string) ([]DiffLine, error) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(ma) > 0 && len(mb) > MaxDiffCells/len(ma) {
+		return nil, ErrDiffTooLarge
+	}
+
+	diff := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	diff = diffMiddle(diff, ma, mb)
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff, nil
+}
+
+// diffMiddle дописывает к diff сравнение a и b по таблице НОП
+func diffMiddle(diff []DiffLine, a, b []string) []DiffLine {
+	// lcs[i*w+j] — длина НОП для a[i:] и b[j:]
+	w := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+	return diff
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	eq := func(s string) DiffLine { return DiffLine{Op: DiffEqual, Text: s} }
+	ins := func(s string) DiffLine { return DiffLine{Op: DiffInsert, Text: s} }
+	del := func(s string) DiffLine { return DiffLine{Op: DiffDelete, Text: s} }
+
+	tests := []struct {
+		name string
+		a, b []string
+		want []DiffLine
+	}{
+		{"both empty", nil, nil, []DiffLine{}},
+		{"empty before", nil, []string{"a", "b"}, []DiffLine{ins("a"), ins("b")}},
+		{"empty after", []string{"a", "b"}, nil, []DiffLine{del("a"), del("b")}},
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, []DiffLine{eq("a"), eq("b")}},
+		{"insert in the middle", []string{"a", "c"}, []string{"a", "b", "c"}, []DiffLine{eq("a"), ins("b"), eq("c")}},
+		{"delete in the middle", []string{"a", "b", "c"}, []string{"a", "c"}, []DiffLine{eq("a"), del("b"), eq("c")}},
+		{"replace", []string{"a", "b", "c"}, []string{"a", "x", "c"}, []DiffLine{eq("a"), del("b"), ins("x"), eq("c")}},
+		{"reorder", []string{"a", "b", "c"}, []string{"c", "a", "b"}, []DiffLine{ins("c"), eq("a"), eq("b"), del("c")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffLines(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeTexts(t *testing.T) {
+	lines := func(prefix string, n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = prefix + strconv.Itoa(i)
+		}
+		return out
+	}
+
+	// Небольшая правка длинного текста сравнивается без полной таблицы
+	a := lines("line ", 100000)
+	b := append([]string(nil), a...)
+	b[50000] = "changed"
+	diff, err := DiffLines(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != len(a)+1 || diff[50000] != (DiffLine{Op: DiffDelete, Text: "line 50000"}) || diff[50001].Text != "changed" {
+		t.Errorf("diff has %d lines, want %d", len(diff), len(a)+1)
+	}
+
+	// Два совершенно разных длинных текста отклоняются
+	if _, err := DiffLines(lines("a", 20000), lines("b", 20000)); !errors.Is(err, ErrDiffTooLarge) {
+		t.Errorf("err = %v, want ErrDiffTooLarge", err)
+	}
+}
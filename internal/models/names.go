@@ -0,0 +1,14 @@
+package models
+
+import "strings"
+
+// CleanName убирает лишние пробелы, сохраняя регистр для отображения
+func CleanName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeName приводит имя исполнителя или название альбома к ключу
+// сравнения: обрезает и схлопывает пробелы, переводит в нижний регистр
+func NormalizeName(name string) string {
+	return strings.ToLower(CleanName(name))
+}
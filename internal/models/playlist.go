@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Playlist struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	EntryCount  int       `json:"entryCount" db:"entry_count"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+	// Entries заполняется только при запросе одного плейлиста
+	Entries []PlaylistEntry `json:"entries,omitempty" db:"-"`
+}
+
+// PlaylistEntry — песня на позиции плейлиста. Position начинается с 1.
+// Available равно false, пока песня лежит в корзине; при окончательном
+// удалении песни запись удаляется из плейлиста.
+type PlaylistEntry struct {
+	ID        int       `json:"id" db:"id"`
+	Position  int       `json:"position" db:"position"`
+	SongID    int       `json:"songId" db:"song_id"`
+	AddedAt   time.Time `json:"addedAt" db:"added_at"`
+	Available bool      `json:"available" db:"available"`
+	// Song — данные песни без текста, заполняются при запросе плейлиста
+	Song *Song `json:"song,omitempty" db:"-"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Действия, которые записываются в историю правок песни
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionRevert  = "revert"
+)
+
+// SongRevision — неизменяемая запись истории правок: полный снимок песни
+// после действия Action
+type SongRevision struct {
+	SongID    int       `json:"songId" db:"song_id"`
+	Revision  int       `json:"revision" db:"revision"`
+	Action    string    `json:"action" db:"action"`
+	Author    string    `json:"author" db:"author"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	Snapshot  Song      `json:"snapshot" db:"-"`
+}
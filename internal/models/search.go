@@ -0,0 +1,18 @@
+package models
+
+// SearchHit — песня, найденная полнотекстовым поиском
+type SearchHit struct {
+	Song
+	Rank float64 `json:"rank" db:"rank"`
+	// Headline — фрагмент текста с совпадениями, обёрнутыми в <mark>
+	Headline string `json:"headline" db:"headline"`
+	// VerseIndex — номер первого совпавшего куплета (с нуля), nil если
+	// совпадение только в названии
+	VerseIndex *int `json:"verseIndex" db:"verse_index"`
+}
+
+// Suggestion — подсказка "возможно, вы имели в виду" для пустой выдачи
+type Suggestion struct {
+	Group string `json:"group,omitempty"`
+	Song  string `json:"song,omitempty"`
+}
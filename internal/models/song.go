@@ -1,10 +1,40 @@
 package models
 
+import "time"
+
+// Состояния фонового дополнения песни данными внешнего API
+const (
+	EnrichmentPending  = "pending"
+	EnrichmentEnriched = "enriched"
+	EnrichmentFailed   = "failed"
+)
+
+// ValidEnrichmentStatus сообщает, известно ли состояние дополнения
+func ValidEnrichmentStatus(status string) bool {
+	return status == EnrichmentPending || status == EnrichmentEnriched || status == EnrichmentFailed
+}
+
 type Song struct {
-	ID          int    `json:"id"`
-	GroupName   string `json:"group"`
-	SongName    string `json:"song"`
-	ReleaseDate string `json:"releaseDate"`
-	Lyrics      string `json:"lyrics"`
-	Link        string `json:"link"`
+	ID          int    `json:"id" db:"id"`
+	ArtistID    int    `json:"artistId" db:"artist_id"`
+	GroupName   string `json:"group" db:"group_name"`
+	SongName    string `json:"song" db:"song_name"`
+	AlbumID     *int   `json:"albumId,omitempty" db:"album_id"`
+	Album       string `json:"album,omitempty" db:"album_title"`
+	TrackNumber *int   `json:"trackNumber,omitempty" db:"track_number"`
+	ReleaseDate string `json:"releaseDate" db:"release_date"`
+	Lyrics      string `json:"lyrics" db:"lyrics"`
+	Link        string `json:"link" db:"link"`
+	// Version увеличивается при каждом изменении песни
+	Version   int       `json:"version" db:"version"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// DeletedAt заполнен у песен, перемещённых в корзину
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+	// EnrichmentStatus — состояние дополнения данными внешнего API;
+	// пусто у песен, которые не ставились в очередь
+	EnrichmentStatus string `json:"enrichmentStatus,omitempty" db:"enrichment_status" enums:"pending,enriched,failed"`
+	// EnrichmentError — последняя ошибка дополнения
+	EnrichmentError    string `json:"enrichmentError,omitempty" db:"enrichment_error"`
+	EnrichmentAttempts int    `json:"enrichmentAttempts,omitempty" db:"enrichment_attempts"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type User struct {
+	ID                 int       `json:"id" db:"id"`
+	Username           string    `json:"username" db:"username"`
+	NormalizedUsername string    `json:"-" db:"normalized_username"`
+	PasswordHash       string    `json:"-" db:"password_hash"`
+	Role               string    `json:"role" db:"role"`
+	CreatedAt          time.Time `json:"createdAt" db:"created_at"`
+}
+
+// RoleChange — запись журнала смены ролей. Хранит имена, чтобы журнал
+// оставался читаемым после удаления пользователей.
+type RoleChange struct {
+	ID                int       `json:"id" db:"id"`
+	UserID            int       `json:"userId" db:"user_id"`
+	Username          string    `json:"username" db:"username"`
+	OldRole           string    `json:"oldRole" db:"old_role"`
+	NewRole           string    `json:"newRole" db:"new_role"`
+	ChangedByID       int       `json:"changedById" db:"changed_by_id"`
+	ChangedByUsername string    `json:"changedBy" db:"changed_by_username"`
+	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
+}
+
+// NormalizeUsername приводит имя пользователя к виду, в котором
+// проверяется уникальность
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
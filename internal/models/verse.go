@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// Verse — куплет (строфа) песни. Строки куплета разделены "\n".
+type Verse struct {
+	SongID   int    `json:"-" db:"song_id"`
+	Position int    `json:"position" db:"position"`
+	Text     string `json:"text" db:"text"`
+}
+
+// Lines возвращает строки куплета
+func (v Verse) Lines() []string {
+	return strings.Split(v.Text, "\n")
+}
+
+// ParseVerses разбивает текст песни на куплеты по пустым строкам.
+// Хвостовые пробелы строк и лишние пустые строки отбрасываются.
+func ParseVerses(lyrics string) []string {
+	lyrics = strings.ReplaceAll(lyrics, "\r\n", "\n")
+
+	var verses, current []string
+	flush := func() {
+		if len(current) > 0 {
+			verses = append(verses, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	for _, line := range strings.Split(lyrics, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return verses
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVerses(t *testing.T) {
+	tests := []struct {
+		name   string
+		lyrics string
+		want   []string
+	}{
+		{"empty", "", nil},
+		{"only blank lines", "\n \n\t\n", nil},
+		{"single verse", "one\ntwo", []string{"one\ntwo"}},
+		{"two verses", "one\ntwo\n\nthree", []string{"one\ntwo", "three"}},
+		{"CRLF", "one\r\ntwo\r\n\r\nthree\r\n", []string{"one\ntwo", "three"}},
+		{"bare CR before LF", "one\r\r\n\r\ntwo", []string{"one", "two"}},
+		{"several blank lines", "one\n\n\n\ntwo", []string{"one", "two"}},
+		{"blank lines with spaces", "one\n  \n\t\ntwo", []string{"one", "two"}},
+		{"leading and trailing blank lines", "\n\none\n\n", []string{"one"}},
+		{"trailing whitespace", "one  \ntwo\t\n\nthree ", []string{"one\ntwo", "three"}},
+		{"leading whitespace kept", "  indented\nnext", []string{"  indented\nnext"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseVerses(tt.lyrics); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseVerses(%q) = %q, want %q", tt.lyrics, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerseLines(t *testing.T) {
+	v := Verse{Text: "one\ntwo"}
+	if got := v.Lines(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("Lines() = %q", got)
+	}
+}
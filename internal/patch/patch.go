@@ -0,0 +1,309 @@
+// Package patch применяет к JSON-документам JSON Merge Patch (RFC 7396)
+// и JSON Patch (RFC 6902)
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch возвращается, если патч некорректен или не применим к документу
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrTestFailed возвращается, если операция test не совпала с документом
+	ErrTestFailed = errors.New("patch test operation failed")
+)
+
+// Merge применяет JSON Merge Patch: объекты сливаются рекурсивно,
+// null удаляет ключ, любое другое значение заменяет прежнее целиком
+func Merge(doc, patch []byte) ([]byte, error) {
+	var d, p interface{}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergeValue(d, p))
+}
+
+func mergeValue(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]interface{})
+	if !ok {
+		d = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+		} else {
+			d[k] = mergeValue(d[k], v)
+		}
+	}
+	return d
+}
+
+// Operation — операция JSON Patch. Value пуст, если поля нет в операции;
+// явный null сохраняется как "null".
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply применяет JSON Patch — массив операций add, remove, replace, move,
+// copy и test. Операции применяются по порядку; при ошибке документ не меняется.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	var d interface{}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		if d, err = applyOperation(d, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(d)
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			// replace — это remove и add по тому же пути
+			if doc, _, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPatch)
+			}
+			if doc, value, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = get(doc, from); err != nil {
+				return nil, err
+			}
+			if value, err = deepCopy(value); err != nil {
+				return nil, err
+			}
+		}
+		return add(doc, path, value)
+	}
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901) на токены
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalidPatch, p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, tok := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[tok]
+			if !ok {
+				return nil, pathNotFound(tok)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(tok, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, pathNotFound(tok)
+		}
+	}
+	return doc, nil
+}
+
+// add возвращает документ со значением, вставленным по пути
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	tok, last := path[0], len(path) == 1
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if last {
+			node[tok] = value
+			return node, nil
+		}
+		child, ok := node[tok]
+		if !ok {
+			return nil, pathNotFound(tok)
+		}
+		child, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		node[tok] = child
+		return node, nil
+	case []interface{}:
+		if last {
+			i := len(node)
+			if tok != "-" {
+				var err error
+				if i, err = arrayIndex(tok, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		i, err := arrayIndex(tok, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		if node[i], err = add(node[i], path[1:], value); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return nil, pathNotFound(tok)
+}
+
+// remove возвращает документ без значения по пути и само удалённое значение
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+	tok, last := path[0], len(path) == 1
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[tok]
+		if !ok {
+			return nil, nil, pathNotFound(tok)
+		}
+		if last {
+			delete(node, tok)
+			return node, child, nil
+		}
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[tok] = child
+		return node, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(tok, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := node[i]
+			return append(node[:i], node[i+1:]...), removed, nil
+		}
+		child, removed, err := remove(node[i], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[i] = child
+		return node, removed, nil
+	}
+	return nil, nil, pathNotFound(tok)
+}
+
+// arrayIndex разбирает индекс массива в диапазоне [0, max]
+func arrayIndex(tok string, max int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	// Ведущие нули и знаки запрещены RFC 6901
+	if err != nil || i < 0 || i > max || strconv.Itoa(i) != tok {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, tok)
+	}
+	return i, nil
+}
+
+func pathNotFound(tok string) error {
+	return fmt.Errorf("%w: path element %q not found", ErrInvalidPatch, tok)
+}
+
+func deepCopy(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var c interface{}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
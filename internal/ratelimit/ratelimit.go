@@ -0,0 +1,167 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму
+// token bucket: у каждого клиента своё ведро, которое вмещает Requests
+// токенов и равномерно наполняется за Period.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval — как часто из памяти удаляются вёдра неактивных клиентов
+const sweepInterval = time.Minute
+
+// Limit — квота: не больше Requests запросов подряд, дальше по одному
+// запросу каждые Period/Requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit разбирает квоту вида "<запросов>/<период>", например "600/1m"
+// или "10/1s". Пустая строка, "0" и "off" означают отсутствие ограничения
+// и дают нулевой Limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" || strings.EqualFold(s, "off") {
+		return Limit{}, nil
+	}
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, errors.New("limit must look like <requests>/<period>")
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid request count %q", requests)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid period %q", period)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Disabled сообщает, что квота не ограничивает запросы
+func (l Limit) Disabled() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// rate — скорость наполнения ведра в токенах в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result — решение по запросу и данные для заголовков RateLimit-*
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset — через сколько ведро наполнится полностью
+	Reset time.Duration
+	// RetryAfter — через сколько появится следующий токен; 0, если запрос разрешён
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter хранит вёдра клиентов одной квоты. Безопасен для конкурентного использования.
+type Limiter struct {
+	limit     Limit
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New возвращает ограничитель квоты limit или nil, если квота отключена.
+// Методы nil-ограничителя разрешают все запросы.
+func New(limit Limit) *Limiter {
+	if limit.Disabled() {
+		return nil
+	}
+	return &Limiter{limit: limit, buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// Limit возвращает квоту ограничителя
+func (l *Limiter) Limit() Limit {
+	if l == nil {
+		return Limit{}
+	}
+	return l.limit
+}
+
+// Allow списывает токен из ведра клиента key, если он есть
+func (l *Limiter) Allow(key string, now time.Time) Result {
+	return l.take(key, now, true)
+}
+
+// Check сообщает, разрешил бы Allow запрос клиента key, не списывая токен
+func (l *Limiter) Check(key string, now time.Time) Result {
+	return l.take(key, now, false)
+}
+
+func (l *Limiter) take(key string, now time.Time, consume bool) Result {
+	if l == nil {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	capacity := float64(l.limit.Requests)
+	rate := l.limit.rate()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+		b.last = now
+	}
+
+	res := Result{Limit: l.limit.Requests}
+	if b.tokens >= 1 {
+		if consume {
+			b.tokens--
+		}
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	return res
+}
+
+// sweep удаляет вёдра, которые уже наполнились: для клиента они
+// неотличимы от нового ведра
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	full := l.limit.Period
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+		ok   bool
+	}{
+		{"600/1m", Limit{Requests: 600, Period: time.Minute}, true},
+		{" 10/1s ", Limit{Requests: 10, Period: time.Second}, true},
+		{"", Limit{}, true},
+		{"0", Limit{}, true},
+		{"OFF", Limit{}, true},
+		{"600", Limit{}, false},
+		{"-1/1m", Limit{}, false},
+		{"ten/1m", Limit{}, false},
+		{"10/0s", Limit{}, false},
+		{"10/minute", Limit{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v; want %v, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAllowBurstAndRefill(t *testing.T) {
+	l := New(Limit{Requests: 3, Period: 3 * time.Second})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		res := l.Allow("a", now)
+		if !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res := l.Allow("a", now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 3*time.Second {
+		t.Errorf("over quota: %+v", res)
+	}
+
+	// Токен появляется через Period/Requests
+	if res := l.Allow("a", now.Add(999*time.Millisecond)); res.Allowed {
+		t.Errorf("allowed before refill: %+v", res)
+	}
+	if res := l.Allow("a", now.Add(time.Second)); !res.Allowed {
+		t.Errorf("denied after refill: %+v", res)
+	}
+
+	// Ведро не наполняется сверх вместимости
+	if res := l.Allow("a", now.Add(time.Hour)); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("after long idle: %+v", res)
+	}
+}
+
+func TestAllowSeparatesClients(t *testing.T) {
+	l := New(Limit{Requests: 1, Period: time.Minute})
+	now := time.Now()
+
+	if !l.Allow("a", now).Allowed || l.Allow("a", now).Allowed {
+		t.Fatal("client a should get exactly one request")
+	}
+	if !l.Allow("b", now).Allowed {
+		t.Error("client b is limited by client a's quota")
+	}
+}
+
+func TestDisabledLimiterAllowsEverything(t *testing.T) {
+	l := New(Limit{})
+	if l != nil {
+		t.Fatalf("New(disabled) = %v, want nil", l)
+	}
+	for i := 0; i < 100; i++ {
+		if !l.Allow("a", time.Now()).Allowed {
+			t.Fatal("nil limiter denied a request")
+		}
+	}
+	if !l.Limit().Disabled() {
+		t.Error("nil limiter reports an active limit")
+	}
+}
+
+func TestSweepDropsFullBuckets(t *testing.T) {
+	l := New(Limit{Requests: 2, Period: time.Second})
+	now := time.Now()
+	l.Allow("idle", now)
+	l.Allow("active", now.Add(sweepInterval))
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("full bucket of an idle client was not swept")
+	}
+	if _, ok := l.buckets["active"]; !ok {
+		t.Error("bucket of an active client was swept")
+	}
+}
+
+func TestCheckDoesNotConsume(t *testing.T) {
+	l := New(Limit{Requests: 1, Period: time.Minute})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if !l.Check("a", now).Allowed {
+			t.Fatalf("check %d denied", i)
+		}
+	}
+	l.Allow("a", now)
+	if res := l.Check("a", now); res.Allowed || res.RetryAfter != time.Minute {
+		t.Errorf("check after quota: %+v", res)
+	}
+}
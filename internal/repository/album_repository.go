@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"music-library/internal/models"
+)
+
+var (
+	// ErrAlbumNotFound возвращается, если альбома с указанным ID нет
+	ErrAlbumNotFound = errors.New("album not found")
+	// ErrAlbumExists возвращается, если у исполнителя уже есть альбом с таким названием
+	ErrAlbumExists = errors.New("album already exists")
+)
+
+// AlbumFilter описывает параметры выборки списка альбомов
+type AlbumFilter struct {
+	ArtistID int
+	Limit    int
+	Offset   int
+}
+
+// AlbumRepository — хранилище альбомов. Названия сравниваются
+// по models.NormalizeName в пределах одного исполнителя.
+type AlbumRepository interface {
+	List(ctx context.Context, filter AlbumFilter) ([]models.Album, error)
+	Get(ctx context.Context, id int) (*models.Album, error)
+	Create(ctx context.Context, album *models.Album) error
+	Update(ctx context.Context, album *models.Album) error
+	Delete(ctx context.Context, id int) error
+	// Resolve возвращает альбом исполнителя с таким же нормализованным названием
+	// или создаёт новый. Пустая дата релиза существующего альбома заполняется releaseDate.
+	Resolve(ctx context.Context, artistID int, title, releaseDate string) (*models.Album, error)
+}
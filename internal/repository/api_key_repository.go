@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"music-library/internal/models"
+)
+
+// ErrAPIKeyNotFound возвращается, если у пользователя нет действующего ключа
+// с указанным ID или предъявленный ключ неизвестен, отозван или истёк
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository — хранилище ключей API. Ключи хранятся только в виде хэша.
+// Методы с userID работают только с ключами этого пользователя.
+type APIKeyRepository interface {
+	// List возвращает ключи пользователя, включая отозванные
+	List(ctx context.Context, userID int) ([]models.APIKey, error)
+	// Create ожидает заполненные UserID, Name, Prefix, Hash и Scopes
+	Create(ctx context.Context, key *models.APIKey) error
+	// Rotate заменяет секрет действующего ключа; старый секрет перестаёт работать сразу
+	Rotate(ctx context.Context, userID, id int, prefix, hash string) (*models.APIKey, error)
+	Revoke(ctx context.Context, userID, id int) error
+	// Lookup находит действующий ключ по хэшу вместе с именем владельца
+	Lookup(ctx context.Context, hash string) (*models.APIKey, error)
+	// Touch записывает время последнего использования ключа
+	Touch(ctx context.Context, id int, at time.Time) error
+}
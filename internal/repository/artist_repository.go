@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"music-library/internal/models"
+)
+
+var (
+	// ErrArtistNotFound возвращается, если исполнителя с указанным ID нет
+	ErrArtistNotFound = errors.New("artist not found")
+	// ErrArtistExists возвращается, если исполнитель с таким нормализованным именем уже есть
+	ErrArtistExists = errors.New("artist already exists")
+	// ErrArtistInUse возвращается при попытке удалить исполнителя, у которого есть песни или альбомы
+	ErrArtistInUse = errors.New("artist has songs or albums")
+)
+
+// ArtistRepository — хранилище исполнителей. Имена сравниваются
+// по models.NormalizeName.
+type ArtistRepository interface {
+	List(ctx context.Context, limit, offset int) ([]models.Artist, error)
+	Get(ctx context.Context, id int) (*models.Artist, error)
+	Create(ctx context.Context, artist *models.Artist) error
+	Update(ctx context.Context, artist *models.Artist) error
+	Delete(ctx context.Context, id int) error
+	// Resolve возвращает исполнителя с таким же нормализованным именем
+	// или создаёт нового
+	Resolve(ctx context.Context, name string) (*models.Artist, error)
+}
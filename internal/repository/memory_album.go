@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"music-library/internal/models"
+)
+
+type MemoryAlbumRepository struct {
+	mu      sync.RWMutex
+	albums  map[int]models.Album
+	byKey   map[string]int
+	nextID  int
+	artists *MemoryArtistRepository
+}
+
+func NewMemoryAlbumRepository(artists *MemoryArtistRepository) *MemoryAlbumRepository {
+	return &MemoryAlbumRepository{
+		albums:  make(map[int]models.Album),
+		byKey:   make(map[string]int),
+		nextID:  1,
+		artists: artists,
+	}
+}
+
+// albumKey — аналог UNIQUE (artist_id, normalized_title)
+func albumKey(artistID int, normalizedTitle string) string {
+	return fmt.Sprintf("%d|%s", artistID, normalizedTitle)
+}
+
+func (r *MemoryAlbumRepository) List(ctx context.Context, filter AlbumFilter) ([]models.Album, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	albums := []models.Album{}
+	for _, al := range r.albums {
+		if filter.ArtistID != 0 && al.ArtistID != filter.ArtistID {
+			continue
+		}
+		albums = append(albums, r.withArtist(ctx, al))
+	}
+	sort.Slice(albums, func(i, j int) bool {
+		a, b := albums[i], albums[j]
+		if a.Artist != b.Artist {
+			return a.Artist < b.Artist
+		}
+		if a.ReleaseDate != b.ReleaseDate {
+			// Альбомы без даты идут последними, как NULLS LAST
+			return b.ReleaseDate == "" || (a.ReleaseDate != "" && a.ReleaseDate < b.ReleaseDate)
+		}
+		return a.ID < b.ID
+	})
+	return paginate(albums, filter.Limit, filter.Offset), nil
+}
+
+func (r *MemoryAlbumRepository) Get(ctx context.Context, id int) (*models.Album, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	al, ok := r.albums[id]
+	if !ok {
+		return nil, ErrAlbumNotFound
+	}
+	al = r.withArtist(ctx, al)
+	return &al, nil
+}
+
+func (r *MemoryAlbumRepository) Create(ctx context.Context, album *models.Album) error {
+	if _, err := r.artists.Get(ctx, album.ArtistID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	album.Title = models.CleanName(album.Title)
+	album.NormalizedTitle = models.NormalizeName(album.Title)
+	if _, ok := r.byKey[albumKey(album.ArtistID, album.NormalizedTitle)]; ok {
+		return ErrAlbumExists
+	}
+	r.insert(album)
+	return nil
+}
+
+func (r *MemoryAlbumRepository) Update(ctx context.Context, album *models.Album) error {
+	if _, err := r.artists.Get(ctx, album.ArtistID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.albums[album.ID]
+	if !ok {
+		return ErrAlbumNotFound
+	}
+	album.Title = models.CleanName(album.Title)
+	album.NormalizedTitle = models.NormalizeName(album.Title)
+	key := albumKey(album.ArtistID, album.NormalizedTitle)
+	if id, ok := r.byKey[key]; ok && id != album.ID {
+		return ErrAlbumExists
+	}
+
+	delete(r.byKey, albumKey(old.ArtistID, old.NormalizedTitle))
+	r.byKey[key] = album.ID
+	r.albums[album.ID] = *album
+	return nil
+}
+
+func (r *MemoryAlbumRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	al, ok := r.albums[id]
+	if !ok {
+		return ErrAlbumNotFound
+	}
+	delete(r.byKey, albumKey(al.ArtistID, al.NormalizedTitle))
+	delete(r.albums, id)
+	return nil
+}
+
+func (r *MemoryAlbumRepository) Resolve(ctx context.Context, artistID int, title, releaseDate string) (*models.Album, error) {
+	if _, err := r.artists.Get(ctx, artistID); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, ok := r.byKey[albumKey(artistID, models.NormalizeName(title))]; ok {
+		al := r.albums[id]
+		if al.ReleaseDate == "" {
+			al.ReleaseDate = releaseDate
+			r.albums[id] = al
+		}
+		al = r.withArtist(ctx, al)
+		return &al, nil
+	}
+
+	album := &models.Album{
+		ArtistID:        artistID,
+		Title:           models.CleanName(title),
+		NormalizedTitle: models.NormalizeName(title),
+		ReleaseDate:     releaseDate,
+	}
+	r.insert(album)
+	*album = r.withArtist(ctx, *album)
+	return album, nil
+}
+
+func (r *MemoryAlbumRepository) insert(album *models.Album) {
+	album.ID = r.nextID
+	r.nextID++
+	r.albums[album.ID] = *album
+	r.byKey[albumKey(album.ArtistID, album.NormalizedTitle)] = album.ID
+}
+
+// withArtist подставляет актуальное имя исполнителя
+func (r *MemoryAlbumRepository) withArtist(ctx context.Context, al models.Album) models.Album {
+	if a, err := r.artists.Get(ctx, al.ArtistID); err == nil {
+		al.Artist = a.Name
+	}
+	return al
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"music-library/internal/models"
+)
+
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[int]models.APIKey
+	nextID int
+	users  *MemoryUserRepository
+}
+
+// NewMemoryAPIKeyRepository принимает хранилище пользователей, из которого
+// подставляется имя владельца ключа
+func NewMemoryAPIKeyRepository(users *MemoryUserRepository) *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys:   make(map[int]models.APIKey),
+		nextID: 1,
+		users:  users,
+	}
+}
+
+func (r *MemoryAPIKeyRepository) List(ctx context.Context, userID int) ([]models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			keys = append(keys, r.withOwner(ctx, k))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if _, err := r.users.Get(ctx, key.UserID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.ID = r.nextID
+	r.nextID++
+	key.CreatedAt = time.Now()
+	key.Scopes = append([]string(nil), key.Scopes...)
+	r.keys[key.ID] = *key
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) Rotate(ctx context.Context, userID, id int, prefix, hash string) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.UserID != userID || !k.Active(time.Now()) {
+		return nil, ErrAPIKeyNotFound
+	}
+	k.Prefix = prefix
+	k.Hash = hash
+	k.LastUsedAt = nil
+	r.keys[id] = k
+	k = r.withOwner(ctx, k)
+	return &k, nil
+}
+
+func (r *MemoryAPIKeyRepository) Revoke(ctx context.Context, userID, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.UserID != userID || k.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	r.keys[id] = k
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) Lookup(ctx context.Context, hash string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Hash == hash && k.Active(time.Now()) {
+			// Ключи удалённого пользователя не действуют, как при ON DELETE CASCADE
+			if _, err := r.users.Get(ctx, k.UserID); err != nil {
+				return nil, ErrAPIKeyNotFound
+			}
+			k = r.withOwner(ctx, k)
+			return &k, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (r *MemoryAPIKeyRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if k, ok := r.keys[id]; ok {
+		k.LastUsedAt = &at
+		r.keys[id] = k
+	}
+	return nil
+}
+
+// withOwner подставляет имя и роль владельца ключа
+func (r *MemoryAPIKeyRepository) withOwner(ctx context.Context, k models.APIKey) models.APIKey {
+	if u, err := r.users.Get(ctx, k.UserID); err == nil {
+		k.Username = u.Username
+		k.Role = u.Role
+	}
+	return k
+}
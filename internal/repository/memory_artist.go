@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"music-library/internal/models"
+)
+
+type MemoryArtistRepository struct {
+	mu           sync.RWMutex
+	artists      map[int]models.Artist
+	byNormalized map[string]int
+	nextID       int
+}
+
+func NewMemoryArtistRepository() *MemoryArtistRepository {
+	return &MemoryArtistRepository{
+		artists:      make(map[int]models.Artist),
+		byNormalized: make(map[string]int),
+		nextID:       1,
+	}
+}
+
+func (r *MemoryArtistRepository) List(ctx context.Context, limit, offset int) ([]models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	artists := make([]models.Artist, 0, len(r.artists))
+	for _, a := range r.artists {
+		artists = append(artists, a)
+	}
+	sort.Slice(artists, func(i, j int) bool {
+		if artists[i].Name != artists[j].Name {
+			return artists[i].Name < artists[j].Name
+		}
+		return artists[i].ID < artists[j].ID
+	})
+	return paginate(artists, limit, offset), nil
+}
+
+func (r *MemoryArtistRepository) Get(ctx context.Context, id int) (*models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.artists[id]
+	if !ok {
+		return nil, ErrArtistNotFound
+	}
+	return &a, nil
+}
+
+func (r *MemoryArtistRepository) Create(ctx context.Context, artist *models.Artist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	artist.Name = models.CleanName(artist.Name)
+	artist.NormalizedName = models.NormalizeName(artist.Name)
+	if _, ok := r.byNormalized[artist.NormalizedName]; ok {
+		return ErrArtistExists
+	}
+	r.insert(artist)
+	return nil
+}
+
+func (r *MemoryArtistRepository) Update(ctx context.Context, artist *models.Artist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.artists[artist.ID]
+	if !ok {
+		return ErrArtistNotFound
+	}
+	artist.Name = models.CleanName(artist.Name)
+	artist.NormalizedName = models.NormalizeName(artist.Name)
+	if id, ok := r.byNormalized[artist.NormalizedName]; ok && id != artist.ID {
+		return ErrArtistExists
+	}
+
+	delete(r.byNormalized, old.NormalizedName)
+	r.byNormalized[artist.NormalizedName] = artist.ID
+	r.artists[artist.ID] = *artist
+	return nil
+}
+
+// Delete не проверяет наличие песен: в памяти нет внешних ключей,
+// поэтому эту проверку выполняет обработчик
+func (r *MemoryArtistRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.artists[id]
+	if !ok {
+		return ErrArtistNotFound
+	}
+	delete(r.byNormalized, a.NormalizedName)
+	delete(r.artists, id)
+	return nil
+}
+
+func (r *MemoryArtistRepository) Resolve(ctx context.Context, name string) (*models.Artist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, ok := r.byNormalized[models.NormalizeName(name)]; ok {
+		a := r.artists[id]
+		return &a, nil
+	}
+	artist := &models.Artist{
+		Name:           models.CleanName(name),
+		NormalizedName: models.NormalizeName(name),
+	}
+	r.insert(artist)
+	return artist, nil
+}
+
+func (r *MemoryArtistRepository) insert(artist *models.Artist) {
+	artist.ID = r.nextID
+	r.nextID++
+	r.artists[artist.ID] = *artist
+	r.byNormalized[artist.NormalizedName] = artist.ID
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"music-library/internal/models"
+)
+
+type MemoryPlaylistRepository struct {
+	mu        sync.Mutex
+	playlists map[int]models.Playlist
+	// entries — записи плейлиста по порядку позиций
+	entries     map[int][]models.PlaylistEntry
+	nextID      int
+	nextEntryID int
+	songs       *MemorySongRepository
+}
+
+// NewMemoryPlaylistRepository принимает хранилище песен, по которому
+// записи помечаются недоступными и удаляются вслед за песнями, как при
+// ON DELETE CASCADE в Postgres
+func NewMemoryPlaylistRepository(songs *MemorySongRepository) *MemoryPlaylistRepository {
+	return &MemoryPlaylistRepository{
+		playlists:   make(map[int]models.Playlist),
+		entries:     make(map[int][]models.PlaylistEntry),
+		nextID:      1,
+		nextEntryID: 1,
+		songs:       songs,
+	}
+}
+
+func (r *MemoryPlaylistRepository) List(ctx context.Context, filter PlaylistFilter) ([]models.Playlist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlists := []models.Playlist{}
+	for id, p := range r.playlists {
+		p.EntryCount = len(r.sync(id))
+		playlists = append(playlists, p)
+	}
+	sort.Slice(playlists, func(i, j int) bool {
+		if playlists[i].Name != playlists[j].Name {
+			return playlists[i].Name < playlists[j].Name
+		}
+		return playlists[i].ID < playlists[j].ID
+	})
+	return paginate(playlists, filter.Limit, filter.Offset), nil
+}
+
+func (r *MemoryPlaylistRepository) Get(ctx context.Context, id int) (*models.Playlist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.playlists[id]
+	if !ok {
+		return nil, ErrPlaylistNotFound
+	}
+	p.EntryCount = len(r.sync(id))
+	return &p, nil
+}
+
+func (r *MemoryPlaylistRepository) Create(ctx context.Context, playlist *models.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist.ID = r.nextID
+	r.nextID++
+	playlist.CreatedAt = time.Now()
+	playlist.UpdatedAt = playlist.CreatedAt
+	r.playlists[playlist.ID] = *playlist
+	return nil
+}
+
+func (r *MemoryPlaylistRepository) Update(ctx context.Context, playlist *models.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.playlists[playlist.ID]
+	if !ok {
+		return ErrPlaylistNotFound
+	}
+	p.Name = playlist.Name
+	p.Description = playlist.Description
+	p.UpdatedAt = time.Now()
+	r.playlists[p.ID] = p
+	return nil
+}
+
+func (r *MemoryPlaylistRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[id]; !ok {
+		return ErrPlaylistNotFound
+	}
+	delete(r.playlists, id)
+	delete(r.entries, id)
+	return nil
+}
+
+func (r *MemoryPlaylistRepository) Entries(ctx context.Context, playlistID int) ([]models.PlaylistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[playlistID]; !ok {
+		return []models.PlaylistEntry{}, nil
+	}
+	entries := r.sync(playlistID)
+	out := make([]models.PlaylistEntry, len(entries))
+	copy(out, entries)
+	return out, nil
+}
+
+func (r *MemoryPlaylistRepository) AddEntry(ctx context.Context, playlistID, songID, position int) (*models.PlaylistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[playlistID]; !ok {
+		return nil, ErrPlaylistNotFound
+	}
+	entries := r.sync(playlistID)
+	if position == 0 {
+		position = len(entries) + 1
+	}
+	if position < 1 || position > len(entries)+1 {
+		return nil, ErrPositionOutOfRange
+	}
+	if exists, trashed := r.songs.state(songID); !exists || trashed {
+		return nil, ErrSongNotFound
+	}
+
+	entry := models.PlaylistEntry{ID: r.nextEntryID, SongID: songID, AddedAt: time.Now(), Available: true}
+	r.nextEntryID++
+	entries = append(entries, models.PlaylistEntry{})
+	copy(entries[position:], entries[position-1:])
+	entries[position-1] = entry
+	r.store(playlistID, entries)
+	out := r.entries[playlistID][position-1]
+	return &out, nil
+}
+
+func (r *MemoryPlaylistRepository) RemoveEntry(ctx context.Context, playlistID, entryID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[playlistID]; !ok {
+		return ErrPlaylistNotFound
+	}
+	entries := r.sync(playlistID)
+	i := entryIndex(entries, entryID)
+	if i < 0 {
+		return ErrEntryNotFound
+	}
+	r.store(playlistID, append(entries[:i:i], entries[i+1:]...))
+	return nil
+}
+
+func (r *MemoryPlaylistRepository) MoveEntry(ctx context.Context, playlistID, entryID, position int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[playlistID]; !ok {
+		return ErrPlaylistNotFound
+	}
+	entries := r.sync(playlistID)
+	i := entryIndex(entries, entryID)
+	if i < 0 {
+		return ErrEntryNotFound
+	}
+	if position < 1 || position > len(entries) {
+		return ErrPositionOutOfRange
+	}
+	if position == i+1 {
+		return nil
+	}
+
+	entry := entries[i]
+	rest := append(entries[:i:i], entries[i+1:]...)
+	moved := make([]models.PlaylistEntry, 0, len(entries))
+	moved = append(moved, rest[:position-1]...)
+	moved = append(moved, entry)
+	moved = append(moved, rest[position-1:]...)
+	r.store(playlistID, moved)
+	return nil
+}
+
+// sync удаляет записи окончательно удалённых песен и обновляет признак
+// доступности, как это делают внешний ключ и JOIN в Postgres
+func (r *MemoryPlaylistRepository) sync(playlistID int) []models.PlaylistEntry {
+	entries := r.entries[playlistID]
+	kept := entries[:0]
+	for _, e := range entries {
+		exists, trashed := r.songs.state(e.SongID)
+		if !exists {
+			continue
+		}
+		e.Available = !trashed
+		kept = append(kept, e)
+	}
+	if len(kept) != len(entries) {
+		r.store(playlistID, kept)
+	}
+	return r.entries[playlistID]
+}
+
+// store сохраняет записи, перенумеровывая позиции подряд с 1
+func (r *MemoryPlaylistRepository) store(playlistID int, entries []models.PlaylistEntry) {
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+	r.entries[playlistID] = entries
+	p := r.playlists[playlistID]
+	p.UpdatedAt = time.Now()
+	r.playlists[playlistID] = p
+}
+
+func entryIndex(entries []models.PlaylistEntry, id int) int {
+	for i, e := range entries {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
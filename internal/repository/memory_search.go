@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"music-library/internal/models"
+)
+
+// Search в памяти приближает полнотекстовый поиск Postgres: песня подходит,
+// если каждое слово запроса встречается в названии или тексте без учёта регистра.
+// Морфология не учитывается, Config игнорируется.
+func (r *MemorySongRepository) Search(ctx context.Context, q SearchQuery) ([]models.SearchHit, error) {
+	terms := searchTerms(q.Text)
+	if len(terms) == 0 {
+		return []models.SearchHit{}, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hits := []models.SearchHit{}
+	for id, s := range r.songs {
+		if s.DeletedAt != nil {
+			continue
+		}
+		name := strings.ToLower(s.SongName)
+		lyrics := strings.ToLower(s.Lyrics)
+		if !containsAll(name+"\n"+lyrics, terms) {
+			continue
+		}
+
+		hit := models.SearchHit{Song: r.withRefs(ctx, s)}
+		for _, t := range terms {
+			// Совпадения в названии весомее, как вес 'A' против 'B' в Postgres
+			hit.Rank += float64(strings.Count(name, t)) + 0.1*float64(strings.Count(lyrics, t))
+		}
+
+		hit.Headline = markTerms(s.SongName, terms)
+		for _, v := range r.verses[id] {
+			if containsAll(strings.ToLower(v.Text), terms) {
+				position := v.Position
+				hit.VerseIndex = &position
+				hit.Headline = markTerms(v.Text, terms)
+				break
+			}
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return paginate(hits, q.Limit, q.Offset), nil
+}
+
+// searchTerms разбивает запрос на слова в нижнем регистре
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func containsAll(s string, terms []string) bool {
+	for _, t := range terms {
+		if !strings.Contains(s, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// markTerms оборачивает вхождения слов запроса в <mark>, как ts_headline
+func markTerms(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Смена регистра изменила длину в байтах, позиции не совпадут
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, t := range terms {
+			if strings.HasPrefix(lower[i:], t) && len(t) > matched {
+				matched = len(t)
+			}
+		}
+		if matched == 0 {
+			b.WriteByte(text[i])
+			i++
+			continue
+		}
+		b.WriteString("<mark>" + text[i:i+matched] + "</mark>")
+		i += matched
+	}
+	return b.String()
+}
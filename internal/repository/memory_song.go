@@ -0,0 +1,531 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"music-library/internal/models"
+)
+
+// MemorySongRepository хранит песни в памяти процесса. Используется в тестах
+// и для запуска API без базы данных.
+type MemorySongRepository struct {
+	mu     sync.RWMutex
+	songs  map[int]models.Song
+	verses map[int][]models.Verse
+	// revisions — история правок песни по возрастанию номера
+	revisions map[int][]models.SongRevision
+	nextID    int
+	artists   *MemoryArtistRepository
+	albums    *MemoryAlbumRepository
+}
+
+// NewMemorySongRepository принимает хранилища исполнителей и альбомов, из которых
+// при чтении подставляются названия группы и альбома, как при JOIN в Postgres
+func NewMemorySongRepository(artists *MemoryArtistRepository, albums *MemoryAlbumRepository) *MemorySongRepository {
+	return &MemorySongRepository{
+		songs:     make(map[int]models.Song),
+		verses:    make(map[int][]models.Verse),
+		revisions: make(map[int][]models.SongRevision),
+		nextID:    1,
+		artists:   artists,
+		albums:    albums,
+	}
+}
+
+func (r *MemorySongRepository) List(ctx context.Context, filter SongFilter) ([]models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songs, scores := r.matching(ctx, filter)
+	order := songSort(filter)
+	byScore := len(filter.Sort) == 0 && filter.Fuzzy && filter.Keyset == nil
+
+	keys := make(map[int][]string, len(songs))
+	for _, s := range songs {
+		keys[s.ID] = SongSortKey(s, order)
+	}
+
+	if filter.Keyset != nil {
+		if err := validateKeyset(order, filter.Keyset.Values); err != nil {
+			return nil, err
+		}
+		page := songs[:0]
+		for _, s := range songs {
+			c := compareSongKeys(keys[s.ID], filter.Keyset.Values, order)
+			if (!filter.Keyset.Before && c > 0) || (filter.Keyset.Before && c < 0) {
+				page = append(page, s)
+			}
+		}
+		songs = page
+	}
+
+	sort.Slice(songs, func(i, j int) bool {
+		if byScore {
+			if si, sj := scores[songs[i].ID], scores[songs[j].ID]; si != sj {
+				return si > sj
+			}
+		}
+		return compareSongKeys(keys[songs[i].ID], keys[songs[j].ID], order) < 0
+	})
+
+	if filter.Keyset != nil && filter.Keyset.Before && filter.Limit > 0 && len(songs) > filter.Limit {
+		songs = songs[len(songs)-filter.Limit:]
+	}
+	if filter.OmitLyrics {
+		for i := range songs {
+			songs[i].Lyrics = ""
+		}
+	}
+	return paginate(songs, filter.Limit, filter.Offset), nil
+}
+
+// Each в памяти обходит результат List: выборка и так хранится в памяти
+func (r *MemorySongRepository) Each(ctx context.Context, filter SongFilter, fn func(models.Song) error) error {
+	songs, err := r.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, song := range songs {
+		if err := fn(song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemorySongRepository) Count(ctx context.Context, filter SongFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songs, _ := r.matching(ctx, filter)
+	return len(songs), nil
+}
+
+// matching отбирает песни по условиям фильтра без учёта пагинации
+// и возвращает сходство для нечёткого поиска
+func (r *MemorySongRepository) matching(ctx context.Context, filter SongFilter) ([]models.Song, map[int]float64) {
+	songs := []models.Song{}
+	scores := make(map[int]float64)
+	for _, s := range r.songs {
+		if !inTrashScope(s, filter.Trash) {
+			continue
+		}
+		s = r.withRefs(ctx, s)
+		if filter.ArtistID != 0 && s.ArtistID != filter.ArtistID {
+			continue
+		}
+		if filter.Fuzzy {
+			score, ok := fuzzyScore(filter, s)
+			if !ok {
+				continue
+			}
+			scores[s.ID] = score
+		} else {
+			if filter.Group != "" && !containsFold(s.GroupName, filter.Group) {
+				continue
+			}
+			if filter.Song != "" && !containsFold(s.SongName, filter.Song) {
+				continue
+			}
+		}
+		if filter.AlbumID != 0 && (s.AlbumID == nil || *s.AlbumID != filter.AlbumID) {
+			continue
+		}
+		if filter.Album != "" && (s.AlbumID == nil || !containsFold(s.Album, filter.Album)) {
+			continue
+		}
+		if len(filter.IDs) > 0 && !containsInt(filter.IDs, s.ID) {
+			continue
+		}
+		// Песни без даты не попадают в диапазон, как NULL в SQL
+		if filter.ReleasedFrom != "" && (s.ReleaseDate == "" || s.ReleaseDate < filter.ReleasedFrom) {
+			continue
+		}
+		if filter.ReleasedTo != "" && (s.ReleaseDate == "" || s.ReleaseDate > filter.ReleasedTo) {
+			continue
+		}
+		if filter.HasLyrics != nil && (s.Lyrics != "") != *filter.HasLyrics {
+			continue
+		}
+		if filter.HasLink != nil && (s.Link != "") != *filter.HasLink {
+			continue
+		}
+		if filter.Enrichment != "" && s.EnrichmentStatus != filter.Enrichment {
+			continue
+		}
+		songs = append(songs, s)
+	}
+	return songs, scores
+}
+
+func (r *MemorySongRepository) Get(ctx context.Context, id int) (*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.songs[id]
+	if !ok || s.DeletedAt != nil {
+		return nil, ErrSongNotFound
+	}
+	s = r.withRefs(ctx, s)
+	return &s, nil
+}
+
+// state сообщает, есть ли песня в хранилище и лежит ли она в корзине
+func (r *MemorySongRepository) state(id int) (exists, trashed bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.songs[id]
+	return ok, ok && s.DeletedAt != nil
+}
+
+func (r *MemorySongRepository) Create(ctx context.Context, song *models.Song) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	song.ID = r.nextID
+	song.Version = 1
+	song.CreatedAt = time.Now()
+	song.UpdatedAt = song.CreatedAt
+	r.nextID++
+	r.songs[song.ID] = *song
+	r.verses[song.ID] = buildVerses(song.ID, song.Lyrics)
+	r.record(ctx, song.ID, models.RevisionCreate)
+	return nil
+}
+
+// CreateBatch сначала разрешает названия исполнителей и альбомов: после этого
+// вставка в память уже не может завершиться ошибкой
+func (r *MemorySongRepository) CreateBatch(ctx context.Context, songs []*models.Song) error {
+	for _, s := range songs {
+		if s.ArtistID == 0 {
+			artist, err := r.artists.Resolve(ctx, s.GroupName)
+			if err != nil {
+				return err
+			}
+			s.ArtistID, s.GroupName = artist.ID, artist.Name
+		}
+		if s.AlbumID == nil && models.CleanName(s.Album) != "" {
+			album, err := r.albums.Resolve(ctx, s.ArtistID, s.Album, s.ReleaseDate)
+			if err != nil {
+				return err
+			}
+			s.AlbumID, s.Album = &album.ID, album.Title
+		}
+	}
+	for _, song := range songs {
+		if err := r.Create(ctx, song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemorySongRepository) Update(ctx context.Context, song *models.Song) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.songs[song.ID]
+	if !ok || current.DeletedAt != nil {
+		return ErrSongNotFound
+	}
+	if song.Version > 0 && song.Version != current.Version {
+		return ErrVersionConflict
+	}
+	song.Version = current.Version + 1
+	song.CreatedAt = current.CreatedAt
+	song.UpdatedAt = time.Now()
+	keepEnrichment(song, current)
+	r.songs[song.ID] = *song
+	r.verses[song.ID] = buildVerses(song.ID, song.Lyrics)
+	r.record(ctx, song.ID, models.RevisionUpdate)
+	return nil
+}
+
+func (r *MemorySongRepository) SetEnrichment(ctx context.Context, id int, status string, attempts int, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.songs[id]
+	if !ok || s.DeletedAt != nil {
+		return ErrSongNotFound
+	}
+	s.EnrichmentStatus = status
+	s.EnrichmentAttempts = attempts
+	s.EnrichmentError = lastError
+	s.Version++
+	s.UpdatedAt = time.Now()
+	r.songs[id] = s
+	return nil
+}
+
+// keepEnrichment переносит в song состояние дополнения из current: его
+// меняет только SetEnrichment, как и в Postgres
+func keepEnrichment(song *models.Song, current models.Song) {
+	song.EnrichmentStatus = current.EnrichmentStatus
+	song.EnrichmentError = current.EnrichmentError
+	song.EnrichmentAttempts = current.EnrichmentAttempts
+}
+
+func (r *MemorySongRepository) Delete(ctx context.Context, id, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.songs[id]
+	if !ok || s.DeletedAt != nil {
+		return ErrSongNotFound
+	}
+	if version > 0 && version != s.Version {
+		return ErrVersionConflict
+	}
+	now := time.Now()
+	s.DeletedAt = &now
+	s.Version++
+	s.UpdatedAt = now
+	r.songs[id] = s
+	r.record(ctx, id, models.RevisionDelete)
+	return nil
+}
+
+func (r *MemorySongRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.songs[id]
+	if !ok || s.DeletedAt == nil {
+		return ErrSongNotFound
+	}
+	s.DeletedAt = nil
+	s.Version++
+	s.UpdatedAt = time.Now()
+	r.songs[id] = s
+	r.record(ctx, id, models.RevisionRestore)
+	return nil
+}
+
+func (r *MemorySongRepository) Purge(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.songs[id]; !ok || s.DeletedAt == nil {
+		return ErrSongNotFound
+	}
+	delete(r.songs, id)
+	delete(r.verses, id)
+	delete(r.revisions, id)
+	return nil
+}
+
+func (r *MemorySongRepository) PurgeTrashed(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, s := range r.songs {
+		if s.DeletedAt != nil && s.DeletedAt.Before(before) {
+			delete(r.songs, id)
+			delete(r.verses, id)
+			delete(r.revisions, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *MemorySongRepository) Revisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]models.SongRevision{}, r.revisions[songID]...), nil
+}
+
+func (r *MemorySongRepository) Revision(ctx context.Context, songID, revision int) (*models.SongRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.revisions[songID]
+	// Номера правок идут подряд с 1
+	if revision < 1 || revision > len(revisions) {
+		return nil, ErrRevisionNotFound
+	}
+	rev := revisions[revision-1]
+	return &rev, nil
+}
+
+func (r *MemorySongRepository) Revert(ctx context.Context, songID, revision int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.songs[songID]
+	if !ok || current.DeletedAt != nil {
+		return ErrSongNotFound
+	}
+	revisions := r.revisions[songID]
+	if revision < 1 || revision > len(revisions) {
+		return ErrRevisionNotFound
+	}
+
+	song := revisions[revision-1].Snapshot
+	if _, err := r.artists.Get(ctx, song.ArtistID); err != nil {
+		return err
+	}
+	if song.AlbumID != nil {
+		if _, err := r.albums.Get(ctx, *song.AlbumID); err != nil {
+			song.AlbumID, song.TrackNumber = nil, nil
+		}
+	}
+	song.DeletedAt = nil
+	song.Version = current.Version + 1
+	song.CreatedAt = current.CreatedAt
+	song.UpdatedAt = time.Now()
+	keepEnrichment(&song, current)
+	r.songs[songID] = song
+	r.verses[songID] = buildVerses(songID, song.Lyrics)
+	r.record(ctx, songID, models.RevisionRevert)
+	return nil
+}
+
+// record добавляет правку со снимком текущего состояния песни.
+// Вызывается под блокировкой на запись.
+func (r *MemorySongRepository) record(ctx context.Context, songID int, action string) {
+	r.revisions[songID] = append(r.revisions[songID], models.SongRevision{
+		SongID:    songID,
+		Revision:  len(r.revisions[songID]) + 1,
+		Action:    action,
+		Author:    authorFrom(ctx),
+		CreatedAt: time.Now(),
+		Snapshot:  r.withRefs(ctx, r.songs[songID]),
+	})
+}
+
+func (r *MemorySongRepository) Verses(ctx context.Context, songID int) ([]models.Verse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]models.Verse{}, r.verses[songID]...), nil
+}
+
+func buildVerses(songID int, lyrics string) []models.Verse {
+	var verses []models.Verse
+	for i, text := range models.ParseVerses(lyrics) {
+		verses = append(verses, models.Verse{SongID: songID, Position: i, Text: text})
+	}
+	return verses
+}
+
+func (r *MemorySongRepository) Suggest(ctx context.Context, group, song string) (*models.Suggestion, error) {
+	var suggestion models.Suggestion
+	if group != "" {
+		artists, err := r.artists.List(ctx, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		best := suggestThreshold
+		for _, a := range artists {
+			if sim := similarity(a.Name, group); sim >= best && (suggestion.Group == "" || sim > best) {
+				suggestion.Group, best = a.Name, sim
+			}
+		}
+	}
+	if song != "" {
+		r.mu.RLock()
+		ids := make([]int, 0, len(r.songs))
+		for id := range r.songs {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		best := suggestThreshold
+		for _, id := range ids {
+			if r.songs[id].DeletedAt != nil {
+				continue
+			}
+			name := r.songs[id].SongName
+			if sim := similarity(name, song); sim >= best && (suggestion.Song == "" || sim > best) {
+				suggestion.Song, best = name, sim
+			}
+		}
+		r.mu.RUnlock()
+	}
+	if suggestion.Group == "" && suggestion.Song == "" {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// fuzzyScore проверяет песню по порогу сходства и возвращает суммарное сходство
+func fuzzyScore(filter SongFilter, s models.Song) (float64, bool) {
+	var score float64
+	if filter.Group != "" {
+		sim := wordSimilarity(filter.Group, s.GroupName)
+		if sim < filter.Threshold {
+			return 0, false
+		}
+		score += sim
+	}
+	if filter.Song != "" {
+		sim := wordSimilarity(filter.Song, s.SongName)
+		if sim < filter.Threshold {
+			return 0, false
+		}
+		score += sim
+	}
+	return score, true
+}
+
+// withRefs подставляет актуальные имя исполнителя и название альбома.
+// Ссылка на удалённый альбом сбрасывается, как ON DELETE SET NULL.
+func (r *MemorySongRepository) withRefs(ctx context.Context, s models.Song) models.Song {
+	if a, err := r.artists.Get(ctx, s.ArtistID); err == nil {
+		s.GroupName = a.Name
+	}
+	s.Album = ""
+	if s.AlbumID != nil {
+		if al, err := r.albums.Get(ctx, *s.AlbumID); err == nil {
+			s.Album = al.Title
+		} else {
+			s.AlbumID = nil
+		}
+	}
+	return s
+}
+
+// inTrashScope проверяет песню по признаку нахождения в корзине
+func inTrashScope(s models.Song, scope TrashScope) bool {
+	switch scope {
+	case WithoutTrashed:
+		return s.DeletedAt == nil
+	case OnlyTrashed:
+		return s.DeletedAt != nil
+	}
+	return true
+}
+
+func containsInt(items []int, v int) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold — регистронезависимый аналог ILIKE '%substr%'
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// paginate применяет LIMIT/OFFSET к уже отсортированному срезу
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
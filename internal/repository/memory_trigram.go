@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// trigrams повторяет разбиение pg_trgm: слова из букв и цифр в нижнем регистре,
+// дополненные двумя пробелами слева и одним справа
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		runes := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// similarity — аналог similarity() из pg_trgm: доля общих триграмм
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := countCommon(ta, tb)
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// wordSimilarity приближает word_similarity() из pg_trgm: доля триграмм
+// запроса, найденных в тексте, поэтому длинный текст не снижает оценку
+func wordSimilarity(query, text string) float64 {
+	tq := trigrams(query)
+	if len(tq) == 0 {
+		return 0
+	}
+	return float64(countCommon(tq, trigrams(text))) / float64(len(tq))
+}
+
+func countCommon(a, b map[string]struct{}) int {
+	n := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			n++
+		}
+	}
+	return n
+}
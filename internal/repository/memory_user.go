@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"music-library/internal/models"
+)
+
+type MemoryUserRepository struct {
+	mu           sync.RWMutex
+	users        map[int]models.User
+	byNormalized map[string]int
+	roleChanges  []models.RoleChange
+	nextID       int
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:        make(map[int]models.User),
+		byNormalized: make(map[string]int),
+		nextID:       1,
+	}
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context, limit, offset int) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return paginate(users, limit, offset), nil
+}
+
+func (r *MemoryUserRepository) Get(ctx context.Context, id int) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (r *MemoryUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byNormalized[models.NormalizeUsername(username)]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	u := r.users[id]
+	return &u, nil
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.NormalizedUsername = models.NormalizeUsername(user.Username)
+	if _, ok := r.byNormalized[user.NormalizedUsername]; ok {
+		return ErrUserExists
+	}
+	if len(r.users) == 0 {
+		user.Role = adminRole
+	}
+	user.ID = r.nextID
+	r.nextID++
+	user.CreatedAt = time.Now()
+	r.users[user.ID] = *user
+	r.byNormalized[user.NormalizedUsername] = user.ID
+	return nil
+}
+
+func (r *MemoryUserRepository) SetRole(ctx context.Context, id int, role string, actor *models.User) (*models.RoleChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	if u.Role == role {
+		return nil, nil
+	}
+	if u.Role == adminRole && r.admins() < 2 {
+		return nil, ErrLastAdmin
+	}
+
+	change := models.RoleChange{
+		ID:                len(r.roleChanges) + 1,
+		UserID:            id,
+		Username:          u.Username,
+		OldRole:           u.Role,
+		NewRole:           role,
+		ChangedByID:       actor.ID,
+		ChangedByUsername: actor.Username,
+		CreatedAt:         time.Now(),
+	}
+	u.Role = role
+	r.users[id] = u
+	r.roleChanges = append(r.roleChanges, change)
+	return &change, nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if u.Role == adminRole && r.admins() < 2 {
+		return ErrLastAdmin
+	}
+	delete(r.byNormalized, u.NormalizedUsername)
+	delete(r.users, id)
+	return nil
+}
+
+func (r *MemoryUserRepository) RoleChanges(ctx context.Context, userID int) ([]models.RoleChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := []models.RoleChange{}
+	for _, ch := range r.roleChanges {
+		if ch.UserID == userID {
+			changes = append(changes, ch)
+		}
+	}
+	return changes, nil
+}
+
+func (r *MemoryUserRepository) admins() int {
+	n := 0
+	for _, u := range r.users {
+		if u.Role == adminRole {
+			n++
+		}
+	}
+	return n
+}
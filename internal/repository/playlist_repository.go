@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"music-library/internal/models"
+)
+
+var (
+	// ErrPlaylistNotFound возвращается, если плейлиста с указанным ID нет
+	ErrPlaylistNotFound = errors.New("playlist not found")
+	// ErrEntryNotFound возвращается, если в плейлисте нет записи с указанным ID
+	ErrEntryNotFound = errors.New("playlist entry not found")
+	// ErrPositionOutOfRange возвращается, если позиция выходит за пределы плейлиста
+	ErrPositionOutOfRange = errors.New("playlist position out of range")
+)
+
+// PlaylistFilter описывает параметры выборки списка плейлистов
+type PlaylistFilter struct {
+	Limit  int
+	Offset int
+}
+
+// PlaylistRepository — хранилище плейлистов и их записей.
+// Позиции записей идут подряд с 1; любое изменение записей сдвигает
+// соседние так, чтобы пропусков не было.
+type PlaylistRepository interface {
+	List(ctx context.Context, filter PlaylistFilter) ([]models.Playlist, error)
+	Get(ctx context.Context, id int) (*models.Playlist, error)
+	Create(ctx context.Context, playlist *models.Playlist) error
+	// Update изменяет название и описание плейлиста
+	Update(ctx context.Context, playlist *models.Playlist) error
+	Delete(ctx context.Context, id int) error
+	// Entries возвращает записи плейлиста по порядку позиций
+	Entries(ctx context.Context, playlistID int) ([]models.PlaylistEntry, error)
+	// AddEntry вставляет песню на позицию position, сдвигая последующие записи;
+	// position 0 добавляет песню в конец. Песню из корзины добавить нельзя:
+	// возвращается ErrSongNotFound.
+	AddEntry(ctx context.Context, playlistID, songID, position int) (*models.PlaylistEntry, error)
+	RemoveEntry(ctx context.Context, playlistID, entryID int) error
+	// MoveEntry перемещает запись на позицию position от 1 до числа записей
+	MoveEntry(ctx context.Context, playlistID, entryID, position int) error
+}
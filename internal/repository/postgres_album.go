@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"music-library/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const albumColumns = `al.id, al.artist_id, a.name AS artist_name, al.title, al.normalized_title,
+	COALESCE(al.release_date::text, '') AS release_date`
+
+const albumFrom = " FROM albums al JOIN artists a ON a.id = al.artist_id"
+
+type PostgresAlbumRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresAlbumRepository(db *sqlx.DB) *PostgresAlbumRepository {
+	return &PostgresAlbumRepository{db: db}
+}
+
+func (r *PostgresAlbumRepository) List(ctx context.Context, filter AlbumFilter) ([]models.Album, error) {
+	query := "SELECT " + albumColumns + albumFrom + " WHERE 1=1"
+	var args []interface{}
+	if filter.ArtistID != 0 {
+		args = append(args, filter.ArtistID)
+		query += fmt.Sprintf(" AND al.artist_id = $%d", len(args))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY a.name, al.release_date NULLS LAST, al.id LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	albums := []models.Album{}
+	if err := r.db.SelectContext(ctx, &albums, query, args...); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+func (r *PostgresAlbumRepository) Get(ctx context.Context, id int) (*models.Album, error) {
+	var album models.Album
+	query := "SELECT " + albumColumns + albumFrom + " WHERE al.id = $1"
+	if err := r.db.GetContext(ctx, &album, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlbumNotFound
+		}
+		return nil, err
+	}
+	return &album, nil
+}
+
+func (r *PostgresAlbumRepository) Create(ctx context.Context, album *models.Album) error {
+	album.Title = models.CleanName(album.Title)
+	album.NormalizedTitle = models.NormalizeName(album.Title)
+
+	query := `INSERT INTO albums (artist_id, title, normalized_title, release_date)
+	          VALUES ($1, $2, $3, NULLIF($4, '')::date) RETURNING id`
+	err := r.db.QueryRowxContext(ctx, query,
+		album.ArtistID, album.Title, album.NormalizedTitle, album.ReleaseDate,
+	).Scan(&album.ID)
+	switch {
+	case isPgError(err, pgUniqueViolation):
+		return ErrAlbumExists
+	case isPgError(err, pgForeignKeyViolation):
+		return ErrArtistNotFound
+	}
+	return err
+}
+
+func (r *PostgresAlbumRepository) Update(ctx context.Context, album *models.Album) error {
+	album.Title = models.CleanName(album.Title)
+	album.NormalizedTitle = models.NormalizeName(album.Title)
+
+	query := `UPDATE albums SET artist_id = $1, title = $2, normalized_title = $3, release_date = NULLIF($4, '')::date
+	          WHERE id = $5`
+	res, err := r.db.ExecContext(ctx, query,
+		album.ArtistID, album.Title, album.NormalizedTitle, album.ReleaseDate, album.ID,
+	)
+	switch {
+	case isPgError(err, pgUniqueViolation):
+		return ErrAlbumExists
+	case isPgError(err, pgForeignKeyViolation):
+		return ErrArtistNotFound
+	case err != nil:
+		return err
+	}
+	return checkAffected(res, ErrAlbumNotFound)
+}
+
+// Delete удаляет альбом; песни остаются в библиотеке без альбома (ON DELETE SET NULL)
+func (r *PostgresAlbumRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM albums WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrAlbumNotFound)
+}
+
+func (r *PostgresAlbumRepository) Resolve(ctx context.Context, artistID int, title, releaseDate string) (*models.Album, error) {
+	id, err := resolveAlbumID(ctx, r.db, artistID, title, releaseDate)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+// resolveAlbumID находит или создаёт альбом через q — базу или транзакцию —
+// и возвращает его ID
+func resolveAlbumID(ctx context.Context, q sqlx.QueryerContext, artistID int, title, releaseDate string) (int, error) {
+	query := `INSERT INTO albums (artist_id, title, normalized_title, release_date)
+	          VALUES ($1, $2, $3, NULLIF($4, '')::date)
+	          ON CONFLICT (artist_id, normalized_title)
+	          DO UPDATE SET release_date = COALESCE(albums.release_date, EXCLUDED.release_date)
+	          RETURNING id`
+	var id int
+	err := q.QueryRowxContext(ctx, query,
+		artistID, models.CleanName(title), models.NormalizeName(title), releaseDate,
+	).Scan(&id)
+	return id, err
+}
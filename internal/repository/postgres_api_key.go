@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"music-library/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `k.id, k.user_id, u.username, u.role, k.name, k.prefix, k.key_hash, k.scopes,
+	k.expires_at, k.created_at, k.last_used_at, k.revoked_at`
+
+const apiKeyFrom = " FROM api_keys k JOIN users u ON u.id = k.user_id"
+
+// apiKeyRow — строка api_keys; области доступа хранятся в TEXT[]
+type apiKeyRow struct {
+	models.APIKey
+	ScopesArray pq.StringArray `db:"scopes"`
+}
+
+func (row apiKeyRow) key() models.APIKey {
+	key := row.APIKey
+	key.Scopes = []string(row.ScopesArray)
+	return key
+}
+
+type PostgresAPIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresAPIKeyRepository(db *sqlx.DB) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db}
+}
+
+func (r *PostgresAPIKeyRepository) List(ctx context.Context, userID int) ([]models.APIKey, error) {
+	var rows []apiKeyRow
+	query := "SELECT " + apiKeyColumns + apiKeyFrom + " WHERE k.user_id = $1 ORDER BY k.id"
+	if err := r.db.SelectContext(ctx, &rows, query, userID); err != nil {
+		return nil, err
+	}
+	keys := make([]models.APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = row.key()
+	}
+	return keys, nil
+}
+
+func (r *PostgresAPIKeyRepository) get(ctx context.Context, where string, args ...interface{}) (*models.APIKey, error) {
+	var row apiKeyRow
+	if err := r.db.GetContext(ctx, &row, "SELECT "+apiKeyColumns+apiKeyFrom+" WHERE "+where, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	key := row.key()
+	return &key, nil
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	err := r.db.QueryRowxContext(ctx, query,
+		key.UserID, key.Name, key.Prefix, key.Hash, pq.StringArray(key.Scopes), key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+	if isPgError(err, pgForeignKeyViolation) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+func (r *PostgresAPIKeyRepository) Rotate(ctx context.Context, userID, id int, prefix, hash string) (*models.APIKey, error) {
+	query := `UPDATE api_keys SET prefix = $1, key_hash = $2, last_used_at = NULL
+	          WHERE id = $3 AND user_id = $4 AND revoked_at IS NULL
+	            AND (expires_at IS NULL OR expires_at > now())`
+	res, err := r.db.ExecContext(ctx, query, prefix, hash, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAffected(res, ErrAPIKeyNotFound); err != nil {
+		return nil, err
+	}
+	return r.get(ctx, "k.id = $1", id)
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, userID, id int) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL", id, userID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrAPIKeyNotFound)
+}
+
+func (r *PostgresAPIKeyRepository) Lookup(ctx context.Context, hash string) (*models.APIKey, error) {
+	return r.get(ctx, "k.key_hash = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > now())", hash)
+}
+
+func (r *PostgresAPIKeyRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", at, id)
+	return err
+}
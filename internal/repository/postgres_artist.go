@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"music-library/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresArtistRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresArtistRepository(db *sqlx.DB) *PostgresArtistRepository {
+	return &PostgresArtistRepository{db: db}
+}
+
+func (r *PostgresArtistRepository) List(ctx context.Context, limit, offset int) ([]models.Artist, error) {
+	artists := []models.Artist{}
+	query := "SELECT id, name, normalized_name FROM artists ORDER BY name, id LIMIT $1 OFFSET $2"
+	if err := r.db.SelectContext(ctx, &artists, query, limit, offset); err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+
+func (r *PostgresArtistRepository) Get(ctx context.Context, id int) (*models.Artist, error) {
+	var artist models.Artist
+	query := "SELECT id, name, normalized_name FROM artists WHERE id = $1"
+	if err := r.db.GetContext(ctx, &artist, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrArtistNotFound
+		}
+		return nil, err
+	}
+	return &artist, nil
+}
+
+func (r *PostgresArtistRepository) Create(ctx context.Context, artist *models.Artist) error {
+	artist.Name = models.CleanName(artist.Name)
+	artist.NormalizedName = models.NormalizeName(artist.Name)
+
+	query := "INSERT INTO artists (name, normalized_name) VALUES ($1, $2) RETURNING id"
+	err := r.db.QueryRowxContext(ctx, query, artist.Name, artist.NormalizedName).Scan(&artist.ID)
+	if isPgError(err, pgUniqueViolation) {
+		return ErrArtistExists
+	}
+	return err
+}
+
+func (r *PostgresArtistRepository) Update(ctx context.Context, artist *models.Artist) error {
+	artist.Name = models.CleanName(artist.Name)
+	artist.NormalizedName = models.NormalizeName(artist.Name)
+
+	query := "UPDATE artists SET name = $1, normalized_name = $2 WHERE id = $3"
+	res, err := r.db.ExecContext(ctx, query, artist.Name, artist.NormalizedName, artist.ID)
+	if isPgError(err, pgUniqueViolation) {
+		return ErrArtistExists
+	}
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrArtistNotFound)
+}
+
+func (r *PostgresArtistRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM artists WHERE id = $1", id)
+	if isPgError(err, pgForeignKeyViolation) {
+		return ErrArtistInUse
+	}
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrArtistNotFound)
+}
+
+func (r *PostgresArtistRepository) Resolve(ctx context.Context, name string) (*models.Artist, error) {
+	return resolveArtist(ctx, r.db, name)
+}
+
+// resolveArtist находит или создаёт исполнителя через q — базу или транзакцию
+func resolveArtist(ctx context.Context, q sqlx.QueryerContext, name string) (*models.Artist, error) {
+	artist := models.Artist{
+		Name:           models.CleanName(name),
+		NormalizedName: models.NormalizeName(name),
+	}
+
+	// DO UPDATE вместо DO NOTHING, чтобы RETURNING вернул и уже существующую строку
+	query := `INSERT INTO artists (name, normalized_name) VALUES ($1, $2)
+	          ON CONFLICT (normalized_name) DO UPDATE SET normalized_name = EXCLUDED.normalized_name
+	          RETURNING id, name`
+	err := q.QueryRowxContext(ctx, query, artist.Name, artist.NormalizedName).Scan(&artist.ID, &artist.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"music-library/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const playlistColumns = `p.id, p.name, p.description, p.created_at, p.updated_at,
+	(SELECT COUNT(*) FROM playlist_entries e WHERE e.playlist_id = p.id) AS entry_count`
+
+type PostgresPlaylistRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPlaylistRepository(db *sqlx.DB) *PostgresPlaylistRepository {
+	return &PostgresPlaylistRepository{db: db}
+}
+
+func (r *PostgresPlaylistRepository) List(ctx context.Context, filter PlaylistFilter) ([]models.Playlist, error) {
+	query := "SELECT " + playlistColumns + " FROM playlists p ORDER BY p.name, p.id LIMIT $1 OFFSET $2"
+	playlists := []models.Playlist{}
+	if err := r.db.SelectContext(ctx, &playlists, query, filter.Limit, filter.Offset); err != nil {
+		return nil, err
+	}
+	return playlists, nil
+}
+
+func (r *PostgresPlaylistRepository) Get(ctx context.Context, id int) (*models.Playlist, error) {
+	var playlist models.Playlist
+	query := "SELECT " + playlistColumns + " FROM playlists p WHERE p.id = $1"
+	if err := r.db.GetContext(ctx, &playlist, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+func (r *PostgresPlaylistRepository) Create(ctx context.Context, playlist *models.Playlist) error {
+	query := `INSERT INTO playlists (name, description) VALUES ($1, $2)
+	          RETURNING id, created_at, updated_at`
+	return r.db.QueryRowxContext(ctx, query, playlist.Name, playlist.Description).
+		Scan(&playlist.ID, &playlist.CreatedAt, &playlist.UpdatedAt)
+}
+
+func (r *PostgresPlaylistRepository) Update(ctx context.Context, playlist *models.Playlist) error {
+	query := `UPDATE playlists SET name = $1, description = $2, updated_at = now() WHERE id = $3`
+	res, err := r.db.ExecContext(ctx, query, playlist.Name, playlist.Description, playlist.ID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrPlaylistNotFound)
+}
+
+func (r *PostgresPlaylistRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM playlists WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrPlaylistNotFound)
+}
+
+func (r *PostgresPlaylistRepository) Entries(ctx context.Context, playlistID int) ([]models.PlaylistEntry, error) {
+	query := `SELECT e.id, e.position, e.song_id, e.added_at, s.deleted_at IS NULL AS available
+	          FROM playlist_entries e JOIN songs s ON s.id = e.song_id
+	          WHERE e.playlist_id = $1 ORDER BY e.position`
+	entries := []models.PlaylistEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, playlistID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *PostgresPlaylistRepository) AddEntry(ctx context.Context, playlistID, songID, position int) (*models.PlaylistEntry, error) {
+	entry := &models.PlaylistEntry{SongID: songID, Available: true}
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		count, err := lockPlaylist(ctx, tx, playlistID)
+		if err != nil {
+			return err
+		}
+		if position == 0 {
+			position = count + 1
+		}
+		if position < 1 || position > count+1 {
+			return ErrPositionOutOfRange
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE playlist_entries SET position = position + 1 WHERE playlist_id = $1 AND position >= $2",
+			playlistID, position,
+		); err != nil {
+			return err
+		}
+		query := `INSERT INTO playlist_entries (playlist_id, song_id, position)
+		          SELECT $1, id, $3 FROM songs WHERE id = $2 AND deleted_at IS NULL
+		          RETURNING id, position, added_at`
+		err = tx.QueryRowxContext(ctx, query, playlistID, songID, position).
+			Scan(&entry.ID, &entry.Position, &entry.AddedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSongNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return touchPlaylist(ctx, tx, playlistID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RemoveEntry удаляет запись; позиции остальных сдвигает триггер playlist_entries_renumber
+func (r *PostgresPlaylistRepository) RemoveEntry(ctx context.Context, playlistID, entryID int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := lockPlaylist(ctx, tx, playlistID); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx,
+			"DELETE FROM playlist_entries WHERE id = $1 AND playlist_id = $2", entryID, playlistID)
+		if err != nil {
+			return err
+		}
+		return checkAffected(res, ErrEntryNotFound)
+	})
+}
+
+func (r *PostgresPlaylistRepository) MoveEntry(ctx context.Context, playlistID, entryID, position int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		count, err := lockPlaylist(ctx, tx, playlistID)
+		if err != nil {
+			return err
+		}
+		var current int
+		err = tx.GetContext(ctx, &current,
+			"SELECT position FROM playlist_entries WHERE id = $1 AND playlist_id = $2", entryID, playlistID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEntryNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if position < 1 || position > count {
+			return ErrPositionOutOfRange
+		}
+		if position == current {
+			return nil
+		}
+
+		// Записи между старой и новой позицией сдвигаются на одну в сторону старой
+		shift := `UPDATE playlist_entries SET position = position + 1
+		          WHERE playlist_id = $1 AND position >= $2 AND position < $3`
+		if position > current {
+			shift = `UPDATE playlist_entries SET position = position - 1
+			         WHERE playlist_id = $1 AND position > $3 AND position <= $2`
+		}
+		if _, err := tx.ExecContext(ctx, shift, playlistID, position, current); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE playlist_entries SET position = $1 WHERE id = $2", position, entryID,
+		); err != nil {
+			return err
+		}
+		return touchPlaylist(ctx, tx, playlistID)
+	})
+}
+
+// lockPlaylist блокирует плейлист до конца транзакции, чтобы параллельные
+// изменения записей не перепутали позиции, и возвращает число записей
+func lockPlaylist(ctx context.Context, tx *sqlx.Tx, playlistID int) (int, error) {
+	var id int
+	err := tx.GetContext(ctx, &id, "SELECT id FROM playlists WHERE id = $1 FOR UPDATE", playlistID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM playlist_entries WHERE playlist_id = $1", playlistID)
+	return count, err
+}
+
+func touchPlaylist(ctx context.Context, tx *sqlx.Tx, playlistID int) error {
+	_, err := tx.ExecContext(ctx, "UPDATE playlists SET updated_at = now() WHERE id = $1", playlistID)
+	return err
+}
@@ -0,0 +1,642 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Nullable-колонки приводим к пустой строке, чтобы их можно было сканировать в string.
+// Название группы и альбома берутся из справочников.
+const songColumns = `s.id, s.artist_id, a.name AS group_name, s.song_name,
+	s.album_id, COALESCE(al.title, '') AS album_title, s.track_number,
+	COALESCE(s.release_date::text, '') AS release_date,
+	COALESCE(s.lyrics, '') AS lyrics,
+	COALESCE(s.link, '') AS link, s.version,
+	s.created_at, s.updated_at, s.deleted_at,
+	COALESCE(s.enrichment_status, '') AS enrichment_status,
+	COALESCE(s.enrichment_error, '') AS enrichment_error, s.enrichment_attempts`
+
+const songFrom = ` FROM songs s
+	JOIN artists a ON a.id = s.artist_id
+	LEFT JOIN albums al ON al.id = s.album_id`
+
+type PostgresSongRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresSongRepository(db *sqlx.DB) *PostgresSongRepository {
+	return &PostgresSongRepository{db: db}
+}
+
+// songConditions добавляет в b условия фильтра. Возвращает выражения
+// сходства для сортировки нечёткого поиска.
+func songConditions(b *queryBuilder, filter SongFilter) (scores []string) {
+	switch filter.Trash {
+	case WithoutTrashed:
+		b.where("s.deleted_at IS NULL")
+	case OnlyTrashed:
+		b.where("s.deleted_at IS NOT NULL")
+	}
+	if filter.ArtistID != 0 {
+		b.where("s.artist_id = " + b.arg(filter.ArtistID))
+	}
+	if filter.Fuzzy {
+		// word_similarity находит опечатки и в части названия: "metalica" ~ "Metallica Tribute"
+		if filter.Group != "" {
+			score := "word_similarity(" + b.arg(filter.Group) + ", a.name)"
+			b.where(score + " >= " + b.arg(filter.Threshold))
+			scores = append(scores, score)
+		}
+		if filter.Song != "" {
+			score := "word_similarity(" + b.arg(filter.Song) + ", s.song_name)"
+			b.where(score + " >= " + b.arg(filter.Threshold))
+			scores = append(scores, score)
+		}
+	} else {
+		if filter.Group != "" {
+			b.where("a.name ILIKE '%' || " + b.arg(filter.Group) + " || '%'")
+		}
+		if filter.Song != "" {
+			b.where("s.song_name ILIKE '%' || " + b.arg(filter.Song) + " || '%'")
+		}
+	}
+	if filter.AlbumID != 0 {
+		b.where("s.album_id = " + b.arg(filter.AlbumID))
+	}
+	if filter.Album != "" {
+		b.where("al.title ILIKE '%' || " + b.arg(filter.Album) + " || '%'")
+	}
+	if len(filter.IDs) > 0 {
+		b.where("s.id = ANY(" + b.arg(pq.Array(filter.IDs)) + "::int[])")
+	}
+	if filter.ReleasedFrom != "" {
+		b.where("s.release_date >= " + b.arg(filter.ReleasedFrom) + "::date")
+	}
+	if filter.ReleasedTo != "" {
+		b.where("s.release_date <= " + b.arg(filter.ReleasedTo) + "::date")
+	}
+	if filter.HasLyrics != nil {
+		b.where(emptinessCondition("s.lyrics", *filter.HasLyrics))
+	}
+	if filter.HasLink != nil {
+		b.where(emptinessCondition("s.link", *filter.HasLink))
+	}
+	if filter.Enrichment != "" {
+		b.where("s.enrichment_status = " + b.arg(filter.Enrichment))
+	}
+	return scores
+}
+
+// emptinessCondition проверяет, что nullable-текстовая колонка заполнена или пуста
+func emptinessCondition(column string, filled bool) string {
+	if filled {
+		return "COALESCE(" + column + ", '') <> ''"
+	}
+	return "COALESCE(" + column + ", '') = ''"
+}
+
+// songSort выбирает порядок по умолчанию, если Sort не задан
+func songSort(filter SongFilter) []SortField {
+	if len(filter.Sort) == 0 && filter.AlbumID != 0 {
+		return []SortField{{Field: "track"}}
+	}
+	return filter.Sort
+}
+
+func (r *PostgresSongRepository) List(ctx context.Context, filter SongFilter) ([]models.Song, error) {
+	query, args, reverse, err := listQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	songs := []models.Song{}
+	if err := r.db.SelectContext(ctx, &songs, query, args...); err != nil {
+		return nil, err
+	}
+	if reverse {
+		for i, j := 0, len(songs)-1; i < j; i, j = i+1, j-1 {
+			songs[i], songs[j] = songs[j], songs[i]
+		}
+	}
+	return songs, nil
+}
+
+// listQuery строит запрос List. reverse означает, что строки выбраны
+// в обратном порядке и их нужно развернуть.
+func listQuery(filter SongFilter) (query string, args []interface{}, reverse bool, err error) {
+	b := &queryBuilder{}
+	scores := songConditions(b, filter)
+	sort := songSort(filter)
+
+	if filter.Keyset != nil {
+		cond, err := keysetCondition(b, sort, *filter.Keyset)
+		if err != nil {
+			return "", nil, false, err
+		}
+		b.where(cond)
+		// Страницу перед курсором выбираем в обратном порядке и разворачиваем после чтения
+		reverse = filter.Keyset.Before
+	}
+
+	orderBy := orderBySQL(sort, reverse)
+	if len(filter.Sort) == 0 && len(scores) > 0 && filter.Keyset == nil {
+		orderBy = " ORDER BY " + strings.Join(scores, " + ") + " DESC, s.id"
+	}
+
+	columns := songColumns
+	if filter.OmitLyrics {
+		columns = strings.Replace(columns, "COALESCE(s.lyrics, '') AS lyrics", "'' AS lyrics", 1)
+	}
+
+	query = "SELECT " + columns + songFrom + b.whereSQL() + orderBy + b.limitSQL(filter.Limit, filter.Offset)
+	return query, b.args, reverse, nil
+}
+
+// eachBatchSize — число строк, читаемых из курсора за один FETCH
+const eachBatchSize = 500
+
+// Each читает выборку серверным курсором порциями по eachBatchSize строк
+func (r *PostgresSongRepository) Each(ctx context.Context, filter SongFilter, fn func(models.Song) error) error {
+	if filter.Keyset != nil && filter.Keyset.Before {
+		return errors.New("each does not support backward keyset pagination")
+	}
+	query, args, _, err := listQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DECLARE songs_each NO SCROLL CURSOR FOR "+query, args...); err != nil {
+			return err
+		}
+		for {
+			batch := []models.Song{}
+			if err := tx.SelectContext(ctx, &batch, "FETCH FORWARD "+strconv.Itoa(eachBatchSize)+" FROM songs_each"); err != nil {
+				return err
+			}
+			for _, song := range batch {
+				if err := fn(song); err != nil {
+					return err
+				}
+			}
+			if len(batch) < eachBatchSize {
+				return nil
+			}
+		}
+	})
+}
+
+func (r *PostgresSongRepository) Count(ctx context.Context, filter SongFilter) (int, error) {
+	b := &queryBuilder{}
+	songConditions(b, filter)
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*)"+songFrom+b.whereSQL(), b.args...)
+	return total, err
+}
+
+func (r *PostgresSongRepository) Get(ctx context.Context, id int) (*models.Song, error) {
+	var song models.Song
+	query := "SELECT " + songColumns + songFrom + " WHERE s.id = $1 AND s.deleted_at IS NULL"
+	if err := r.db.GetContext(ctx, &song, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSongNotFound
+		}
+		return nil, err
+	}
+	return &song, nil
+}
+
+func (r *PostgresSongRepository) Create(ctx context.Context, song *models.Song) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `INSERT INTO songs (artist_id, song_name, album_id, track_number, release_date, lyrics, link, enrichment_status)
+		          VALUES ($1, $2, $3, $4, NULLIF($5, '')::date, $6, $7, NULLIF($8, ''))
+		          RETURNING id, version, created_at, updated_at`
+		err := tx.QueryRowxContext(ctx, query,
+			song.ArtistID, song.SongName, song.AlbumID, song.TrackNumber, song.ReleaseDate, song.Lyrics, song.Link,
+			song.EnrichmentStatus,
+		).Scan(&song.ID, &song.Version, &song.CreatedAt, &song.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if err := replaceVerses(ctx, tx, song.ID, song.Lyrics); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, song.ID, models.RevisionCreate)
+	})
+}
+
+// songBatchSize ограничивает число строк в одном INSERT, чтобы не выйти
+// за предел в 65535 параметров запроса
+const songBatchSize = 500
+
+// CreateBatch вставляет песни, их куплеты и правки многострочными INSERT.
+// Postgres возвращает строки RETURNING в порядке VALUES.
+func (r *PostgresSongRepository) CreateBatch(ctx context.Context, songs []*models.Song) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := resolveBatchNames(ctx, tx, songs); err != nil {
+			return err
+		}
+		for start := 0; start < len(songs); start += songBatchSize {
+			batch := songs[start:min(start+songBatchSize, len(songs))]
+			if err := insertSongs(ctx, tx, batch); err != nil {
+				return err
+			}
+			if err := insertVerses(ctx, tx, batch); err != nil {
+				return err
+			}
+			if err := insertCreateRevisions(ctx, tx, batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// resolveBatchNames находит или создаёт в транзакции исполнителей и альбомы
+// песен, заданных только названиями, чтобы откат пакета не оставлял их без песен.
+// Одинаковые названия разрешаются один раз.
+func resolveBatchNames(ctx context.Context, tx *sqlx.Tx, songs []*models.Song) error {
+	artists := make(map[string]*models.Artist)
+	albums := make(map[string]int)
+	for _, s := range songs {
+		if s.ArtistID == 0 {
+			key := models.NormalizeName(s.GroupName)
+			artist, ok := artists[key]
+			if !ok {
+				var err error
+				if artist, err = resolveArtist(ctx, tx, s.GroupName); err != nil {
+					return err
+				}
+				artists[key] = artist
+			}
+			s.ArtistID, s.GroupName = artist.ID, artist.Name
+		}
+		if s.AlbumID == nil && models.CleanName(s.Album) != "" {
+			key := strconv.Itoa(s.ArtistID) + "/" + models.NormalizeName(s.Album)
+			id, ok := albums[key]
+			if !ok {
+				var err error
+				if id, err = resolveAlbumID(ctx, tx, s.ArtistID, s.Album, s.ReleaseDate); err != nil {
+					return err
+				}
+				albums[key] = id
+			}
+			s.AlbumID = &id
+		}
+	}
+	return nil
+}
+
+func insertSongs(ctx context.Context, tx *sqlx.Tx, songs []*models.Song) error {
+	b := &queryBuilder{}
+	rows := make([]string, len(songs))
+	for i, s := range songs {
+		rows[i] = "(" + strings.Join([]string{
+			b.arg(s.ArtistID), b.arg(s.SongName), b.arg(s.AlbumID), b.arg(s.TrackNumber),
+			"NULLIF(" + b.arg(s.ReleaseDate) + ", '')::date", b.arg(s.Lyrics), b.arg(s.Link),
+			"NULLIF(" + b.arg(s.EnrichmentStatus) + ", '')",
+		}, ", ") + ")"
+	}
+	query := `INSERT INTO songs (artist_id, song_name, album_id, track_number, release_date, lyrics, link, enrichment_status)
+	          VALUES ` + strings.Join(rows, ", ") + `
+	          RETURNING id, version, created_at, updated_at`
+
+	res, err := tx.QueryxContext(ctx, query, b.args...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	for _, s := range songs {
+		if !res.Next() {
+			return errors.New("insert returned fewer rows than songs")
+		}
+		if err := res.Scan(&s.ID, &s.Version, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return res.Err()
+}
+
+func insertVerses(ctx context.Context, tx *sqlx.Tx, songs []*models.Song) error {
+	b := &queryBuilder{}
+	var rows []string
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		query := "INSERT INTO song_verses (song_id, position, text) VALUES " + strings.Join(rows, ", ")
+		_, err := tx.ExecContext(ctx, query, b.args...)
+		b, rows = &queryBuilder{}, nil
+		return err
+	}
+	for _, s := range songs {
+		for i, text := range models.ParseVerses(s.Lyrics) {
+			rows = append(rows, "("+b.arg(s.ID)+", "+b.arg(i)+", "+b.arg(text)+")")
+			if len(rows) == songBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return flush()
+}
+
+func insertCreateRevisions(ctx context.Context, tx *sqlx.Tx, songs []*models.Song) error {
+	b := &queryBuilder{}
+	rows := make([]string, len(songs))
+	author := authorFrom(ctx)
+	for i, s := range songs {
+		snapshot, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		rows[i] = "(" + b.arg(s.ID) + ", 1, " + b.arg(models.RevisionCreate) + ", " +
+			b.arg(author) + ", " + b.arg(string(snapshot)) + "::jsonb)"
+	}
+	query := "INSERT INTO song_revisions (song_id, revision, action, author, snapshot) VALUES " + strings.Join(rows, ", ")
+	_, err := tx.ExecContext(ctx, query, b.args...)
+	return err
+}
+
+func (r *PostgresSongRepository) Update(ctx context.Context, song *models.Song) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `UPDATE songs SET artist_id = $1, song_name = $2, album_id = $3, track_number = $4,
+		          release_date = NULLIF($5, '')::date, lyrics = $6, link = $7,
+		          version = version + 1, updated_at = now()
+		          WHERE id = $8 AND deleted_at IS NULL AND ($9 = 0 OR version = $9)
+		          RETURNING version, created_at, updated_at`
+		err := tx.QueryRowxContext(ctx, query,
+			song.ArtistID, song.SongName, song.AlbumID, song.TrackNumber, song.ReleaseDate, song.Lyrics, song.Link,
+			song.ID, song.Version,
+		).Scan(&song.Version, &song.CreatedAt, &song.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return missingOrConflict(ctx, tx, song.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := replaceVerses(ctx, tx, song.ID, song.Lyrics); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, song.ID, models.RevisionUpdate)
+	})
+}
+
+func (r *PostgresSongRepository) SetEnrichment(ctx context.Context, id int, status string, attempts int, lastError string) error {
+	query := `UPDATE songs SET enrichment_status = $1, enrichment_attempts = $2, enrichment_error = NULLIF($3, ''),
+	          version = version + 1, updated_at = now()
+	          WHERE id = $4 AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, status, attempts, lastError, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrSongNotFound)
+}
+
+func (r *PostgresSongRepository) Delete(ctx context.Context, id, version int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `UPDATE songs SET deleted_at = now(), version = version + 1, updated_at = now()
+		          WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+		res, err := tx.ExecContext(ctx, query, id, version)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return missingOrConflict(ctx, tx, id)
+		}
+		return recordRevision(ctx, tx, id, models.RevisionDelete)
+	})
+}
+
+// missingOrConflict объясняет, почему условное изменение не затронуло песню:
+// её нет (или она в корзине) либо изменилась её версия
+func missingOrConflict(ctx context.Context, tx *sqlx.Tx, id int) error {
+	var exists bool
+	if err := tx.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)", id); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrSongNotFound
+	}
+	return ErrVersionConflict
+}
+
+func (r *PostgresSongRepository) Restore(ctx context.Context, id int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `UPDATE songs SET deleted_at = NULL, version = version + 1, updated_at = now()
+		          WHERE id = $1 AND deleted_at IS NOT NULL`
+		res, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		if err := checkAffected(res, ErrSongNotFound); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, id, models.RevisionRestore)
+	})
+}
+
+// Purge удаляет песню вместе с куплетами (ON DELETE CASCADE)
+func (r *PostgresSongRepository) Purge(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM songs WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, ErrSongNotFound)
+}
+
+func (r *PostgresSongRepository) PurgeTrashed(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM songs WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// revisionRow — строка song_revisions; снимок хранится в JSONB
+type revisionRow struct {
+	models.SongRevision
+	SnapshotJSON []byte `db:"snapshot"`
+}
+
+func (row revisionRow) revision() (models.SongRevision, error) {
+	rev := row.SongRevision
+	err := json.Unmarshal(row.SnapshotJSON, &rev.Snapshot)
+	return rev, err
+}
+
+const revisionColumns = "song_id, revision, action, author, created_at, snapshot"
+
+func (r *PostgresSongRepository) Revisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	var rows []revisionRow
+	query := "SELECT " + revisionColumns + " FROM song_revisions WHERE song_id = $1 ORDER BY revision"
+	if err := r.db.SelectContext(ctx, &rows, query, songID); err != nil {
+		return nil, err
+	}
+	revisions := make([]models.SongRevision, len(rows))
+	for i, row := range rows {
+		rev, err := row.revision()
+		if err != nil {
+			return nil, err
+		}
+		revisions[i] = rev
+	}
+	return revisions, nil
+}
+
+func (r *PostgresSongRepository) Revision(ctx context.Context, songID, revision int) (*models.SongRevision, error) {
+	return getRevision(ctx, r.db, songID, revision)
+}
+
+func getRevision(ctx context.Context, q sqlx.QueryerContext, songID, revision int) (*models.SongRevision, error) {
+	var row revisionRow
+	query := "SELECT " + revisionColumns + " FROM song_revisions WHERE song_id = $1 AND revision = $2"
+	if err := sqlx.GetContext(ctx, q, &row, query, songID, revision); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	rev, err := row.revision()
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (r *PostgresSongRepository) Revert(ctx context.Context, songID, revision int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		rev, err := getRevision(ctx, tx, songID, revision)
+		if err != nil {
+			return err
+		}
+		song := rev.Snapshot
+
+		// Альбом мог быть удалён после этой правки — тогда песня остаётся без альбома
+		if song.AlbumID != nil {
+			var exists bool
+			if err := tx.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM albums WHERE id = $1)", *song.AlbumID); err != nil {
+				return err
+			}
+			if !exists {
+				song.AlbumID, song.TrackNumber = nil, nil
+			}
+		}
+
+		query := `UPDATE songs SET artist_id = $1, song_name = $2, album_id = $3, track_number = $4,
+		          release_date = NULLIF($5, '')::date, lyrics = $6, link = $7,
+		          version = version + 1, updated_at = now()
+		          WHERE id = $8 AND deleted_at IS NULL`
+		res, err := tx.ExecContext(ctx, query,
+			song.ArtistID, song.SongName, song.AlbumID, song.TrackNumber, song.ReleaseDate, song.Lyrics, song.Link, songID,
+		)
+		if isPgError(err, pgForeignKeyViolation) {
+			return ErrArtistNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkAffected(res, ErrSongNotFound); err != nil {
+			return err
+		}
+		if err := replaceVerses(ctx, tx, songID, song.Lyrics); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, songID, models.RevisionRevert)
+	})
+}
+
+// recordRevision сохраняет снимок песни в том виде, в каком она читается через Get.
+// Номер правки следующий по порядку: параллельные изменения одной песни
+// упорядочены блокировкой её строки в songs.
+func recordRevision(ctx context.Context, tx *sqlx.Tx, songID int, action string) error {
+	var song models.Song
+	if err := tx.GetContext(ctx, &song, "SELECT "+songColumns+songFrom+" WHERE s.id = $1", songID); err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO song_revisions (song_id, revision, action, author, snapshot)
+	          SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4::jsonb FROM song_revisions WHERE song_id = $1`
+	_, err = tx.ExecContext(ctx, query, songID, action, authorFrom(ctx), string(snapshot))
+	return err
+}
+
+func (r *PostgresSongRepository) Verses(ctx context.Context, songID int) ([]models.Verse, error) {
+	verses := []models.Verse{}
+	query := "SELECT song_id, position, text FROM song_verses WHERE song_id = $1 ORDER BY position"
+	if err := r.db.SelectContext(ctx, &verses, query, songID); err != nil {
+		return nil, err
+	}
+	return verses, nil
+}
+
+// replaceVerses пересобирает куплеты песни из текста
+func replaceVerses(ctx context.Context, tx *sqlx.Tx, songID int, lyrics string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_verses WHERE song_id = $1", songID); err != nil {
+		return err
+	}
+	for i, text := range models.ParseVerses(lyrics) {
+		query := "INSERT INTO song_verses (song_id, position, text) VALUES ($1, $2, $3)"
+		if _, err := tx.ExecContext(ctx, query, songID, i, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresSongRepository) Search(ctx context.Context, q SearchQuery) ([]models.SearchHit, error) {
+	query := `WITH q AS (SELECT websearch_to_tsquery($1::regconfig, $2) AS query)
+	SELECT ` + songColumns + `,
+		ts_rank(s.search_vector, q.query) AS rank,
+		ts_headline($1::regconfig, COALESCE(NULLIF(s.lyrics, ''), s.song_name), q.query,
+			'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, FragmentDelimiter=" … "') AS headline,
+		(SELECT v.position FROM song_verses v
+		 WHERE v.song_id = s.id AND v.search_vector @@ q.query
+		 ORDER BY v.position LIMIT 1) AS verse_index` + songFrom + `, q
+	WHERE s.search_vector @@ q.query AND s.deleted_at IS NULL
+	ORDER BY rank DESC, s.id
+	LIMIT $3 OFFSET $4`
+
+	hits := []models.SearchHit{}
+	if err := r.db.SelectContext(ctx, &hits, query, q.Config, q.Text, q.Limit, q.Offset); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+func (r *PostgresSongRepository) Suggest(ctx context.Context, group, song string) (*models.Suggestion, error) {
+	var suggestion models.Suggestion
+	if group != "" {
+		query := `SELECT name FROM artists WHERE similarity(name, $1) >= $2 ORDER BY similarity(name, $1) DESC, id LIMIT 1`
+		if err := r.db.GetContext(ctx, &suggestion.Group, query, group, suggestThreshold); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	if song != "" {
+		query := `SELECT song_name FROM songs WHERE deleted_at IS NULL AND similarity(song_name, $1) >= $2 ORDER BY similarity(song_name, $1) DESC, id LIMIT 1`
+		if err := r.db.GetContext(ctx, &suggestion.Song, query, song, suggestThreshold); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	if suggestion.Group == "" && suggestion.Song == "" {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"music-library/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const userColumns = "id, username, normalized_username, password_hash, role, created_at"
+
+// adminRole — роль, последнего носителя которой нельзя лишить прав
+const adminRole = "admin"
+
+type PostgresUserRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresUserRepository(db *sqlx.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([]models.User, error) {
+	users := []models.User{}
+	query := "SELECT " + userColumns + " FROM users ORDER BY id LIMIT $1 OFFSET $2"
+	if err := r.db.SelectContext(ctx, &users, query, limit, offset); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) Get(ctx context.Context, id int) (*models.User, error) {
+	return r.get(ctx, r.db, "SELECT "+userColumns+" FROM users WHERE id = $1", id)
+}
+
+func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.get(ctx, r.db, "SELECT "+userColumns+" FROM users WHERE normalized_username = $1",
+		models.NormalizeUsername(username))
+}
+
+func (r *PostgresUserRepository) get(ctx context.Context, q sqlx.QueryerContext, query string, arg interface{}) (*models.User, error) {
+	var user models.User
+	if err := sqlx.GetContext(ctx, q, &user, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Create назначает самому первому пользователю роль admin. Блокировка таблицы
+// не даёт двум параллельным регистрациям обеим увидеть пустую таблицу и
+// получить роль admin; она конфликтует только с другими изменениями users.
+func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User) error {
+	user.NormalizedUsername = models.NormalizeUsername(user.Username)
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+		query := `INSERT INTO users (username, normalized_username, password_hash, role)
+		          VALUES ($1, $2, $3, CASE WHEN EXISTS (SELECT 1 FROM users) THEN $4 ELSE $5 END)
+		          RETURNING id, role, created_at`
+		return tx.QueryRowxContext(ctx, query, user.Username, user.NormalizedUsername, user.PasswordHash, user.Role, adminRole).
+			Scan(&user.ID, &user.Role, &user.CreatedAt)
+	})
+	if isPgError(err, pgUniqueViolation) {
+		return ErrUserExists
+	}
+	return err
+}
+
+func (r *PostgresUserRepository) SetRole(ctx context.Context, id int, role string, actor *models.User) (*models.RoleChange, error) {
+	var change *models.RoleChange
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		user, err := r.get(ctx, tx, "SELECT "+userColumns+" FROM users WHERE id = $1 FOR UPDATE", id)
+		if err != nil {
+			return err
+		}
+		if user.Role == role {
+			return nil
+		}
+		if user.Role == adminRole {
+			if err := ensureOtherAdmin(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, id); err != nil {
+			return err
+		}
+		change = &models.RoleChange{
+			UserID:            id,
+			Username:          user.Username,
+			OldRole:           user.Role,
+			NewRole:           role,
+			ChangedByID:       actor.ID,
+			ChangedByUsername: actor.Username,
+		}
+		query := `INSERT INTO role_changes (user_id, username, old_role, new_role, changed_by_id, changed_by_username)
+		          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+		return tx.QueryRowxContext(ctx, query,
+			change.UserID, change.Username, change.OldRole, change.NewRole, change.ChangedByID, change.ChangedByUsername,
+		).Scan(&change.ID, &change.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (r *PostgresUserRepository) Delete(ctx context.Context, id int) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		user, err := r.get(ctx, tx, "SELECT "+userColumns+" FROM users WHERE id = $1 FOR UPDATE", id)
+		if err != nil {
+			return err
+		}
+		if user.Role == adminRole {
+			if err := ensureOtherAdmin(ctx, tx); err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
+		return err
+	})
+}
+
+// ensureOtherAdmin возвращает ErrLastAdmin, если админ только один.
+// FOR UPDATE блокирует админов, чтобы две параллельные транзакции
+// не разжаловали двух последних админов одновременно.
+func ensureOtherAdmin(ctx context.Context, tx *sqlx.Tx) error {
+	var ids []int
+	if err := tx.SelectContext(ctx, &ids, "SELECT id FROM users WHERE role = $1 FOR UPDATE", adminRole); err != nil {
+		return err
+	}
+	if len(ids) < 2 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) RoleChanges(ctx context.Context, userID int) ([]models.RoleChange, error) {
+	changes := []models.RoleChange{}
+	query := `SELECT id, user_id, username, old_role, new_role, changed_by_id, changed_by_username, created_at
+	          FROM role_changes WHERE user_id = $1 ORDER BY id`
+	if err := r.db.SelectContext(ctx, &changes, query, userID); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queryBuilder собирает условия WHERE и аргументы запроса, нумеруя
+// плейсхолдеры Postgres по мере добавления аргументов. Значения никогда
+// не подставляются в текст запроса.
+type queryBuilder struct {
+	conds []string
+	args  []interface{}
+}
+
+// arg добавляет аргумент и возвращает его плейсхолдер ($1, $2, ...).
+// Один плейсхолдер можно использовать в запросе несколько раз.
+func (b *queryBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where добавляет условие, объединяемое с остальными через AND
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+// whereSQL возвращает " WHERE ..." или пустую строку, если условий нет
+func (b *queryBuilder) whereSQL() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+// limitSQL добавляет LIMIT/OFFSET. limit <= 0 снимает ограничение.
+func (b *queryBuilder) limitSQL(limit, offset int) string {
+	// LIMIT NULL в Postgres означает отсутствие ограничения
+	var l interface{}
+	if limit > 0 {
+		l = limit
+	}
+	return " LIMIT " + b.arg(l) + " OFFSET " + b.arg(offset)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"music-library/internal/models"
+)
+
+var (
+	// ErrSongNotFound возвращается, если песни с указанным ID нет в хранилище
+	ErrSongNotFound = errors.New("song not found")
+	// ErrRevisionNotFound возвращается, если у песни нет правки с указанным номером
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrVersionConflict возвращается, если песня изменилась после чтения
+	// клиентом ожидаемой версии
+	ErrVersionConflict = errors.New("song version conflict")
+)
+
+// anonymousAuthor записывается в историю правок, если автор не указан в контексте
+const anonymousAuthor = "anonymous"
+
+type authorKey struct{}
+
+// WithAuthor возвращает контекст с автором изменений для истории правок
+func WithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorKey{}, author)
+}
+
+// authorFrom возвращает автора изменений из контекста
+func authorFrom(ctx context.Context) string {
+	if author, ok := ctx.Value(authorKey{}).(string); ok && author != "" {
+		return author
+	}
+	return anonymousAuthor
+}
+
+// TrashScope определяет, попадают ли в выборку песни из корзины
+type TrashScope int
+
+const (
+	// WithoutTrashed — только песни вне корзины, значение по умолчанию
+	WithoutTrashed TrashScope = iota
+	// OnlyTrashed — только песни из корзины
+	OnlyTrashed
+	// WithTrashed — все песни
+	WithTrashed
+)
+
+// SongFilter описывает параметры выборки списка песен
+type SongFilter struct {
+	ArtistID int
+	Group    string
+	Song     string
+	// Fuzzy включает поиск Group и Song по триграммному сходству вместо
+	// подстроки; результаты сортируются по сходству
+	Fuzzy     bool
+	Threshold float64
+	// AlbumID выбирает треклист альбома в порядке номеров треков
+	AlbumID int
+	// Album — подстрока названия альбома
+	Album string
+	IDs   []int
+	// ReleasedFrom и ReleasedTo — границы даты релиза включительно, YYYY-MM-DD
+	ReleasedFrom string
+	ReleasedTo   string
+	HasLyrics    *bool
+	HasLink      *bool
+	// Enrichment выбирает песни с указанным состоянием дополнения
+	Enrichment string
+	Trash      TrashScope
+	// Sort задаёт порядок; пустой Sort означает сортировку по ID,
+	// по сходству для Fuzzy и по номеру трека для AlbumID
+	Sort []SortField
+	// Keyset выбирает страницу после (или до) позиции курсора в порядке Sort.
+	// Страница всегда возвращается в прямом порядке.
+	Keyset *Keyset
+	// OmitLyrics не читает тексты песен, Lyrics остаётся пустым
+	OmitLyrics bool
+	// Limit <= 0 снимает ограничение на количество строк
+	Limit  int
+	Offset int
+}
+
+// suggestThreshold — минимальное сходство для подсказки "возможно, вы имели в виду".
+// Ниже типичного порога поиска, чтобы подсказка находилась, когда поиск ничего не дал.
+const suggestThreshold = 0.15
+
+// SearchQuery описывает полнотекстовый поиск по текстам и названиям песен
+type SearchQuery struct {
+	Text string
+	// Config — конфигурация текстового поиска Postgres: "russian" или "english"
+	Config string
+	Limit  int
+	Offset int
+}
+
+// SongRepository — хранилище песен, от которого зависят обработчики
+type SongRepository interface {
+	List(ctx context.Context, filter SongFilter) ([]models.Song, error)
+	// Each вызывает fn для каждой песни по фильтру в порядке List, не загружая
+	// выборку в память целиком. Ошибка fn прерывает обход и возвращается.
+	Each(ctx context.Context, filter SongFilter, fn func(models.Song) error) error
+	// Count возвращает число песен по фильтру без учёта пагинации
+	Count(ctx context.Context, filter SongFilter) (int, error)
+	// Get, Update, Verses и Search не видят песни из корзины
+	Get(ctx context.Context, id int) (*models.Song, error)
+	// Create и Update ожидают заполненный ArtistID; GroupName при чтении
+	// берётся из справочника исполнителей. Обе проставляют song.Version
+	// и время изменения.
+	Create(ctx context.Context, song *models.Song) error
+	// CreateBatch создаёт песни в одной транзакции: либо все, либо ни одной.
+	// Песня без ArtistID получает исполнителя по GroupName, без AlbumID — альбом
+	// по непустому Album; их находят или создают в той же транзакции.
+	CreateBatch(ctx context.Context, songs []*models.Song) error
+	// Update с song.Version > 0 изменяет песню, только если её текущая версия
+	// совпадает с song.Version, иначе возвращает ErrVersionConflict
+	Update(ctx context.Context, song *models.Song) error
+	// SetEnrichment записывает состояние дополнения песни данными внешнего
+	// API. Состояние входит в представление песни, поэтому её версия и время
+	// изменения растут, но правка в историю не пишется. Create сохраняет состояние
+	// из song, Update его не меняет.
+	SetEnrichment(ctx context.Context, id int, status string, attempts int, lastError string) error
+	// Create, Update, Delete, Restore и Revert атомарно записывают правку
+	// с автором из контекста (WithAuthor).
+	// Delete перемещает песню в корзину. Restore возвращает её обратно,
+	// Purge удаляет из корзины навсегда.
+	// Delete с version > 0 проверяет версию так же, как Update.
+	Delete(ctx context.Context, id, version int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	// PurgeTrashed навсегда удаляет песни, перемещённые в корзину раньше before,
+	// и возвращает их количество
+	PurgeTrashed(ctx context.Context, before time.Time) (int, error)
+	// Revisions возвращает историю правок песни по возрастанию номера,
+	// включая песни в корзине
+	Revisions(ctx context.Context, songID int) ([]models.SongRevision, error)
+	Revision(ctx context.Context, songID, revision int) (*models.SongRevision, error)
+	// Revert возвращает песню к снимку правки и записывает новую правку.
+	// Удалённый с тех пор альбом не восстанавливается.
+	Revert(ctx context.Context, songID, revision int) error
+	// Verses возвращает куплеты песни по порядку. Куплеты пересобираются
+	// из Lyrics при каждом Create и Update.
+	Verses(ctx context.Context, songID int) ([]models.Verse, error)
+	// Search ищет песни по тексту и названию, сортируя по релевантности
+	Search(ctx context.Context, query SearchQuery) ([]models.SearchHit, error)
+	// Suggest подбирает наиболее похожие существующие названия группы и песни
+	// для подсказки "возможно, вы имели в виду". Возвращает nil, если похожих нет.
+	Suggest(ctx context.Context, group, song string) (*models.Suggestion, error)
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"music-library/internal/models"
+)
+
+// SortField — поле сортировки списка песен
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Keyset — позиция курсора: значения ключа сортировки (SongSortKey) последней
+// или первой песни предыдущей страницы. Before выбирает страницу перед позицией.
+type Keyset struct {
+	Values []string
+	Before bool
+}
+
+// sortColumn описывает поле из белого списка сортировки. Выражения не
+// содержат NULL, чтобы их можно было сравнивать в keyset-условиях.
+type sortColumn struct {
+	expr    string
+	cast    string
+	numeric bool
+	key     func(s models.Song) string
+}
+
+const (
+	// Песни без даты релиза или номера трека идут последними
+	noReleaseDate  = "infinity"
+	noTrackNumber  = 2147483647
+	defaultSortKey = "id"
+)
+
+var songSortColumns = map[string]sortColumn{
+	"id": {
+		expr: "s.id", cast: "int", numeric: true,
+		key: func(s models.Song) string { return strconv.Itoa(s.ID) },
+	},
+	"group": {
+		expr: "a.name", cast: "text",
+		key: func(s models.Song) string { return s.GroupName },
+	},
+	"song": {
+		expr: "s.song_name", cast: "text",
+		key: func(s models.Song) string { return s.SongName },
+	},
+	"album": {
+		expr: "COALESCE(al.title, '')", cast: "text",
+		key: func(s models.Song) string { return s.Album },
+	},
+	"track": {
+		expr: fmt.Sprintf("COALESCE(s.track_number, %d)", noTrackNumber), cast: "int", numeric: true,
+		key: func(s models.Song) string {
+			if s.TrackNumber == nil {
+				return strconv.Itoa(noTrackNumber)
+			}
+			return strconv.Itoa(*s.TrackNumber)
+		},
+	},
+	"release_date": {
+		expr: "COALESCE(s.release_date, 'infinity'::date)", cast: "date",
+		key: func(s models.Song) string {
+			if s.ReleaseDate == "" {
+				return noReleaseDate
+			}
+			return s.ReleaseDate
+		},
+	},
+}
+
+// SongSortFields — допустимые поля параметра sort
+var SongSortFields = []string{"id", "group", "song", "album", "track", "release_date"}
+
+// ParseSongSort разбирает строку вида "release_date,-song". Минус означает
+// сортировку по убыванию. Пустая строка — сортировка по умолчанию.
+func ParseSongSort(s string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		f := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := songSortColumns[f.Field]; !ok {
+			return nil, fmt.Errorf("unknown sort field %q", f.Field)
+		}
+		if seen[f.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", f.Field)
+		}
+		seen[f.Field] = true
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// FormatSongSort — обратная к ParseSongSort операция
+func FormatSongSort(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// withTiebreak дополняет сортировку полем id, чтобы порядок был полным
+// и keyset-пагинация не теряла песни с одинаковыми значениями
+func withTiebreak(fields []SortField) []SortField {
+	for _, f := range fields {
+		if f.Field == defaultSortKey {
+			return fields
+		}
+	}
+	return append(append([]SortField{}, fields...), SortField{Field: defaultSortKey})
+}
+
+// SongSortKey возвращает значения ключа сортировки песни для курсора
+func SongSortKey(song models.Song, fields []SortField) []string {
+	fields = withTiebreak(fields)
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		values[i] = songSortColumns[f.Field].key(song)
+	}
+	return values
+}
+
+// ValidateKeyset проверяет значения курсора для сортировки списка по filter.
+// Курсор приходит от клиента, поэтому его значения нельзя передавать
+// в приведение типов Postgres без проверки.
+func ValidateKeyset(filter SongFilter, values []string) error {
+	return validateKeyset(songSort(filter), values)
+}
+
+// validateKeyset проверяет, что значений столько же, сколько полей
+// сортировки, и каждое приводится к типу своего столбца
+func validateKeyset(fields []SortField, values []string) error {
+	fields = withTiebreak(fields)
+	if len(values) != len(fields) {
+		return fmt.Errorf("cursor has %d values, sort has %d fields", len(values), len(fields))
+	}
+	for i, f := range fields {
+		if err := validSortValue(songSortColumns[f.Field], values[i]); err != nil {
+			return fmt.Errorf("cursor value for %s: %w", f.Field, err)
+		}
+	}
+	return nil
+}
+
+// validSortValue проверяет значение ключа по типу столбца col.cast
+func validSortValue(col sortColumn, v string) error {
+	switch col.cast {
+	case "int":
+		// int в Postgres — 32 бита
+		_, err := strconv.ParseInt(v, 10, 32)
+		return err
+	case "date":
+		if v == noReleaseDate {
+			return nil
+		}
+		_, err := time.Parse(time.DateOnly, v)
+		return err
+	}
+	if !utf8.ValidString(v) || strings.ContainsRune(v, 0) {
+		return errors.New("invalid text")
+	}
+	return nil
+}
+
+// keysetCondition строит условие "строка после (или до) позиции курсора"
+// для составного ключа с разными направлениями сортировки:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(b *queryBuilder, fields []SortField, keyset Keyset) (string, error) {
+	if err := validateKeyset(fields, keyset.Values); err != nil {
+		return "", err
+	}
+	fields = withTiebreak(fields)
+
+	var (
+		ors    []string
+		prefix []string
+	)
+	for i, f := range fields {
+		col := songSortColumns[f.Field]
+		p := b.arg(keyset.Values[i]) + "::" + col.cast
+		op := ">"
+		if f.Desc != keyset.Before {
+			op = "<"
+		}
+		ors = append(ors, "("+strings.Join(append(append([]string{}, prefix...), col.expr+" "+op+" "+p), " AND ")+")")
+		prefix = append(prefix, col.expr+" = "+p)
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", nil
+}
+
+// orderBySQL возвращает ORDER BY для сортировки; reverse разворачивает
+// направления для выборки страницы перед курсором
+func orderBySQL(fields []SortField, reverse bool) string {
+	fields = withTiebreak(fields)
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		dir := "ASC"
+		if f.Desc != reverse {
+			dir = "DESC"
+		}
+		parts[i] = songSortColumns[f.Field].expr + " " + dir
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// compareSongKeys сравнивает ключи сортировки двух песен с учётом направлений.
+// Используется хранилищем в памяти вместо ORDER BY.
+func compareSongKeys(a, b []string, fields []SortField) int {
+	fields = withTiebreak(fields)
+	for i, f := range fields {
+		c := compareSortValues(songSortColumns[f.Field], a[i], b[i])
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareSortValues(col sortColumn, a, b string) int {
+	if col.numeric {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	if col.cast == "date" && a != b {
+		// 'infinity' больше любой даты; остальные даты в ISO сравниваются как строки
+		if a == noReleaseDate {
+			return 1
+		}
+		if b == noReleaseDate {
+			return -1
+		}
+	}
+	return strings.Compare(a, b)
+}
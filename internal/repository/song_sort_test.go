@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+func TestParseSongSort(t *testing.T) {
+	fields, err := ParseSongSort(" release_date, -song ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SortField{{Field: "release_date"}, {Field: "song", Desc: true}}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %+v, want %+v", fields, want)
+	}
+	if got := FormatSongSort(fields); got != "release_date,-song" {
+		t.Errorf("FormatSongSort = %q", got)
+	}
+
+	for _, s := range []string{"lyrics", "song,-song", "-"} {
+		if _, err := ParseSongSort(s); err == nil {
+			t.Errorf("ParseSongSort(%q) succeeded", s)
+		}
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	b := &queryBuilder{}
+	cond, err := keysetCondition(b, []SortField{{Field: "song", Desc: true}}, Keyset{Values: []string{"Uprising", "7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "((s.song_name < $1::text) OR (s.song_name = $1::text AND s.id > $2::int))"
+	if cond != want {
+		t.Errorf("condition = %s\nwant        %s", cond, want)
+	}
+	if !reflect.DeepEqual(b.args, []interface{}{"Uprising", "7"}) {
+		t.Errorf("args = %v", b.args)
+	}
+
+	// Страница перед курсором разворачивает сравнения
+	b = &queryBuilder{}
+	cond, err = keysetCondition(b, nil, Keyset{Values: []string{"7"}, Before: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cond != "((s.id < $1::int))" {
+		t.Errorf("before condition = %s", cond)
+	}
+}
+
+func TestValidateKeyset(t *testing.T) {
+	byDate := SongFilter{Sort: []SortField{{Field: "release_date"}, {Field: "track"}}}
+	tests := []struct {
+		name   string
+		filter SongFilter
+		values []string
+		ok     bool
+	}{
+		{"valid", byDate, []string{"2009-09-07", "3", "12"}, true},
+		{"no release date", byDate, []string{noReleaseDate, "2147483647", "12"}, true},
+		{"too few values", byDate, []string{"2009-09-07", "3"}, false},
+		{"bad date", byDate, []string{"07.09.2009", "3", "12"}, false},
+		{"bad int", byDate, []string{"2009-09-07", "three", "12"}, false},
+		{"int overflow", byDate, []string{"2009-09-07", "2147483648", "12"}, false},
+		{"text with NUL", SongFilter{Sort: []SortField{{Field: "song"}}}, []string{"a\x00b", "1"}, false},
+		{"invalid UTF-8", SongFilter{Sort: []SortField{{Field: "group"}}}, []string{"\xff", "1"}, false},
+		{"album default sort", SongFilter{AlbumID: 1}, []string{"2", "5"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKeyset(tt.filter, tt.values)
+			if (err == nil) != tt.ok {
+				t.Errorf("ValidateKeyset(%q) = %v, want ok=%v", tt.values, err, tt.ok)
+			}
+		})
+	}
+}
+
+// Курсор, построенный по последней песне страницы, открывает следующую
+// страницу без пропусков и повторов, в том числе для одинаковых значений ключа
+func TestKeysetPaginationRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	artists := NewMemoryArtistRepository()
+	albums := NewMemoryAlbumRepository(artists)
+	songs := NewMemorySongRepository(artists, albums)
+
+	artist, err := artists.Resolve(ctx, "Muse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dates := []string{"2009-09-07", "", "2006-07-03", "2009-09-07", "", "2001-06-18", "2009-09-07"}
+	for i, d := range dates {
+		s := &models.Song{ArtistID: artist.ID, SongName: string(rune('A' + i)), ReleaseDate: d}
+		if err := songs.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	order := []SortField{{Field: "release_date", Desc: true}}
+	all, err := songs.List(ctx, SongFilter{Sort: order})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		paged  []models.Song
+		keyset *Keyset
+	)
+	for page := 0; ; page++ {
+		if page > len(dates) {
+			t.Fatal("pagination does not terminate")
+		}
+		batch, err := songs.List(ctx, SongFilter{Sort: order, Limit: 2, Keyset: keyset})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		paged = append(paged, batch...)
+		keyset = &Keyset{Values: SongSortKey(batch[len(batch)-1], order)}
+	}
+
+	if len(paged) != len(all) {
+		t.Fatalf("paged %d songs, want %d", len(paged), len(all))
+	}
+	for i := range all {
+		if paged[i].ID != all[i].ID {
+			t.Fatalf("song %d: paged ID %d, want %d", i, paged[i].ID, all[i].ID)
+		}
+	}
+
+	// Страница перед курсором возвращает предыдущие песни в прямом порядке
+	last := paged[len(paged)-1]
+	before, err := songs.List(ctx, SongFilter{Sort: order, Limit: 2, Keyset: &Keyset{Values: SongSortKey(last, order), Before: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 2 || before[0].ID != all[len(all)-3].ID || before[1].ID != all[len(all)-2].ID {
+		t.Errorf("page before last song = %+v", before)
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"music-library/internal/models"
+)
+
+var (
+	// ErrUserNotFound возвращается, если пользователя нет
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists возвращается, если имя пользователя уже занято
+	ErrUserExists = errors.New("user already exists")
+	// ErrLastAdmin возвращается при попытке разжаловать или удалить последнего админа
+	ErrLastAdmin = errors.New("cannot remove the last admin")
+)
+
+// UserRepository — хранилище учётных записей. Имена сравниваются
+// по models.NormalizeUsername.
+type UserRepository interface {
+	List(ctx context.Context, limit, offset int) ([]models.User, error)
+	Get(ctx context.Context, id int) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	// Create ожидает заполненные PasswordHash и Role и проставляет ID и время
+	// создания. Первый пользователь хранилища получает роль admin, чтобы
+	// было кому раздавать роли.
+	Create(ctx context.Context, user *models.User) error
+	// SetRole меняет роль пользователя и записывает смену в журнал от имени actor.
+	// Возвращает nil, если роль не изменилась.
+	SetRole(ctx context.Context, id int, role string, actor *models.User) (*models.RoleChange, error)
+	Delete(ctx context.Context, id int) error
+	// RoleChanges возвращает журнал смены ролей пользователя по времени
+	RoleChanges(ctx context.Context, userID int) ([]models.RoleChange, error)
+}
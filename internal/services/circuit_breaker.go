@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"music-library/internal/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen — внешний API признан недоступным, запрос не отправлялся
+var ErrCircuitOpen = errors.New("external API circuit breaker is open")
+
+// BreakerState — состояние автомата защиты
+type BreakerState int
+
+const (
+	// BreakerClosed — запросы проходят, неудачи подряд считаются
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen — после паузы пропускается один пробный запрос
+	BreakerHalfOpen
+	// BreakerOpen — запросы отклоняются сразу до конца паузы
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerSnapshot — состояние автомата и его счётчики на момент запроса
+type BreakerSnapshot struct {
+	State BreakerState
+	// Failures — неудачи подряд в замкнутом состоянии
+	Failures int
+	// RetryAfter — сколько осталось до пробного запроса в разомкнутом состоянии
+	RetryAfter time.Duration
+	// Opens — сколько раз автомат размыкался
+	Opens uint64
+	// Rejected — сколько запросов отклонено без обращения к API
+	Rejected uint64
+}
+
+// CircuitBreaker размыкается после threshold неудач подряд и отклоняет
+// запросы, пока не пройдёт coolDown. Затем один пробный запрос решает,
+// замкнуться снова или выждать ещё одну паузу.
+type CircuitBreaker struct {
+	threshold int
+	coolDown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	opens    uint64
+	rejected uint64
+}
+
+// NewCircuitBreaker создаёт замкнутый автомат. threshold <= 0 отключает
+// размыкание.
+func NewCircuitBreaker(threshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, coolDown: coolDown}
+}
+
+// Allow решает, можно ли отправить запрос. Каждый разрешённый запрос
+// завершается вызовом Done.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.coolDown {
+		b.state = BreakerHalfOpen
+		logger.Log.Info("External API circuit breaker is half-open")
+	}
+	switch {
+	case b.state == BreakerOpen, b.state == BreakerHalfOpen && b.probing:
+		b.rejected++
+		return ErrCircuitOpen
+	case b.state == BreakerHalfOpen:
+		b.probing = true
+	}
+	return nil
+}
+
+// Done сообщает исход запроса, разрешённого Allow: failed — API недоступен
+func (b *CircuitBreaker) Done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasProbe := b.state == BreakerHalfOpen && b.probing
+	if wasProbe {
+		b.probing = false
+	}
+
+	if !failed {
+		if b.state != BreakerClosed {
+			logger.Log.Info("External API circuit breaker is closed")
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if wasProbe || b.state == BreakerClosed && b.threshold > 0 && b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.opens++
+		logger.Log.WithFields(logrus.Fields{
+			"failures":  b.failures,
+			"cool_down": b.coolDown,
+		}).Warn("External API circuit breaker is open")
+	}
+}
+
+// Snapshot возвращает текущее состояние автомата
+func (b *CircuitBreaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := BreakerSnapshot{State: b.state, Failures: b.failures, Opens: b.opens, Rejected: b.rejected}
+	if b.state == BreakerOpen {
+		s.RetryAfter = max(b.coolDown-time.Since(b.openedAt), 0)
+		if s.RetryAfter == 0 {
+			// Пауза истекла, следующий запрос станет пробным
+			s.State = BreakerHalfOpen
+		}
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := NewCircuitBreaker(3, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("request %d rejected: %v", i, err)
+		}
+		b.Done(true)
+	}
+	// Успех сбрасывает счётчик неудач подряд
+	b.Allow()
+	b.Done(false)
+	if s := b.Snapshot(); s.State != BreakerClosed || s.Failures != 0 {
+		t.Fatalf("after success: %+v", s)
+	}
+
+	for i := 0; i < 3; i++ {
+		b.Allow()
+		b.Done(true)
+	}
+	s := b.Snapshot()
+	if s.State != BreakerOpen || s.Opens != 1 || s.RetryAfter <= 0 {
+		t.Fatalf("after threshold: %+v", s)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("open breaker allowed a request: %v", err)
+	}
+	if s := b.Snapshot(); s.Rejected != 1 {
+		t.Errorf("rejected = %d, want 1", s.Rejected)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	const coolDown = 20 * time.Millisecond
+	b := NewCircuitBreaker(1, coolDown)
+	b.Allow()
+	b.Done(true)
+
+	time.Sleep(coolDown)
+	if s := b.Snapshot(); s.State != BreakerHalfOpen {
+		t.Fatalf("after cool-down: state %s, want half-open", s.State)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	// Пока идёт пробный запрос, остальные отклоняются
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second request during probe: %v", err)
+	}
+
+	// Неудачный пробный запрос размыкает автомат на ещё одну паузу
+	b.Done(true)
+	if s := b.Snapshot(); s.State != BreakerOpen || s.Opens != 2 {
+		t.Fatalf("after failed probe: %+v", s)
+	}
+
+	time.Sleep(coolDown)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("second probe rejected: %v", err)
+	}
+	b.Done(false)
+	if s := b.Snapshot(); s.State != BreakerClosed {
+		t.Fatalf("after successful probe: state %s, want closed", s.State)
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("closed breaker rejected a request: %v", err)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Hour)
+	for i := 0; i < 100; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("request %d rejected: %v", i, err)
+		}
+		b.Done(true)
+	}
+}
+
+// Ответы 5xx повторяются и размыкают автомат; 404 — ответ API, а не сбой
+func TestExternalClientTripsBreakerOnServerErrors(t *testing.T) {
+	var requests, status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	client, err := NewExternalClient(ExternalClientConfig{
+		BaseURL:          srv.URL,
+		Retries:          1,
+		Backoff:          time.Millisecond,
+		BreakerThreshold: 2,
+		BreakerCoolDown:  time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var statusErr *StatusError
+	if _, err := client.FetchSong(ctx, "Muse", "Uprising"); !errors.As(err, &statusErr) || statusErr.StatusCode != 500 {
+		t.Fatalf("err = %v, want 500", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2 with one retry", n)
+	}
+	client.FetchSong(ctx, "Muse", "Hysteria")
+	if _, err := client.FetchSong(ctx, "Muse", "Madness"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after threshold: err = %v, want ErrCircuitOpen", err)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("open breaker sent a request: %d requests", n)
+	}
+
+	status.Store(http.StatusNotFound)
+	client, _ = NewExternalClient(ExternalClientConfig{BaseURL: srv.URL, Retries: 3, BreakerThreshold: 1, BreakerCoolDown: time.Hour})
+	requests.Store(0)
+	for _, song := range []string{"A", "B"} {
+		if _, err := client.FetchSong(ctx, "Muse", song); !errors.As(err, &statusErr) || statusErr.StatusCode != 404 {
+			t.Fatalf("err = %v, want 404", err)
+		}
+	}
+	if n := requests.Load(); n != 2 || client.Breaker().Snapshot().State != BreakerClosed {
+		t.Errorf("404: %d requests, breaker %s; want 2 requests and a closed breaker", n, client.Breaker().Snapshot().State)
+	}
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// enrichmentAuthor — автор правок, сделанных фоновым дополнением песен
+const enrichmentAuthor = "enrichment"
+
+// enrichConflictRetries — сколько раз дополнение перечитывает песню, если её
+// изменили во время запроса к API
+const enrichConflictRetries = 3
+
+// EnricherConfig — настройки фонового дополнения
+type EnricherConfig struct {
+	// Workers — число одновременных обработчиков очереди
+	Workers int
+	// QueueSize — вместимость очереди
+	QueueSize int
+	// MaxAttempts — после стольких неудачных попыток песня получает
+	// состояние failed
+	MaxAttempts int
+	// RetryDelay — пауза перед первым повтором; каждая следующая вдвое длиннее
+	RetryDelay time.Duration
+	// SweepInterval — как часто в очередь возвращаются песни в состоянии
+	// pending, не попавшие в неё из-за переполнения
+	SweepInterval time.Duration
+}
+
+// Enricher в фоне дополняет песни в состоянии pending данными внешнего API:
+// заполняет дату релиза, текст, ссылку и альбом, если они пусты. Неудачные
+// попытки повторяются с нарастающей паузой, состояние и последняя ошибка
+// сохраняются в песне.
+type Enricher struct {
+	songs    repository.SongRepository
+	albums   repository.AlbumRepository
+	external *ExternalClient
+	cfg      EnricherConfig
+	queue    chan int
+
+	mu sync.Mutex
+	// tracked — песни в очереди, в обработке или в ожидании повтора;
+	// обход не ставит их в очередь второй раз
+	tracked map[int]struct{}
+}
+
+// NewEnricher создаёт очередь на cfg.QueueSize песен
+func NewEnricher(songs repository.SongRepository, albums repository.AlbumRepository, external *ExternalClient, cfg EnricherConfig) *Enricher {
+	cfg.Workers = max(cfg.Workers, 1)
+	cfg.MaxAttempts = max(cfg.MaxAttempts, 1)
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 30 * time.Second
+	}
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = 5 * time.Minute
+	}
+	return &Enricher{
+		songs:    songs,
+		albums:   albums,
+		external: external,
+		cfg:      cfg,
+		queue:    make(chan int, cfg.QueueSize),
+		tracked:  make(map[int]struct{}),
+	}
+}
+
+// Enqueue ставит песню в очередь. Возвращает false, если очередь заполнена;
+// песня остаётся в состоянии pending и попадёт в очередь при следующем обходе.
+func (e *Enricher) Enqueue(songID int) bool {
+	if !e.track(songID) {
+		return true
+	}
+	select {
+	case e.queue <- songID:
+		return true
+	default:
+		e.untrack(songID)
+		return false
+	}
+}
+
+// Unavailable сообщает, разомкнут ли автомат защиты внешнего API, и через
+// сколько он пропустит пробный запрос
+func (e *Enricher) Unavailable() (time.Duration, bool) {
+	s := e.external.Breaker().Snapshot()
+	return s.RetryAfter, s.State == BreakerOpen
+}
+
+// Run обрабатывает очередь cfg.Workers обработчиками, пока не отменён ctx.
+// Песни в состоянии pending, оставшиеся с прошлого запуска или не попавшие
+// в переполненную очередь, возвращаются в неё раз в cfg.SweepInterval.
+func (e *Enricher) Run(ctx context.Context) {
+	go e.sweep(ctx)
+
+	var wg sync.WaitGroup
+	for range e.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-e.queue:
+					if !e.process(ctx, id) {
+						e.untrack(id)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// sweep вызывает requeuePending при запуске и затем каждые cfg.SweepInterval
+func (e *Enricher) sweep(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.SweepInterval)
+	defer ticker.Stop()
+	for {
+		e.requeuePending(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// requeuePending возвращает в очередь песни в состоянии pending, которых в ней
+// нет. Ждёт места в очереди, а не отбрасывает песни.
+func (e *Enricher) requeuePending(ctx context.Context) {
+	songs, err := e.songs.List(ctx, repository.SongFilter{Enrichment: models.EnrichmentPending, OmitLyrics: true})
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to load songs pending enrichment")
+		return
+	}
+	requeued := 0
+	for _, s := range songs {
+		if !e.track(s.ID) {
+			continue
+		}
+		if !e.push(ctx, s.ID) {
+			e.untrack(s.ID)
+			return
+		}
+		requeued++
+	}
+	if requeued > 0 {
+		logger.Log.WithFields(logrus.Fields{"songs": requeued}).Info("Requeued songs pending enrichment")
+	}
+}
+
+// push ждёт места в очереди. Возвращает false, если ctx отменён.
+func (e *Enricher) push(ctx context.Context, id int) bool {
+	select {
+	case e.queue <- id:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// track отмечает песню как поставленную в очередь. Возвращает false, если
+// она уже в очереди, в обработке или ждёт повтора.
+func (e *Enricher) track(id int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.tracked[id]; ok {
+		return false
+	}
+	e.tracked[id] = struct{}{}
+	return true
+}
+
+func (e *Enricher) untrack(id int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.tracked, id)
+}
+
+// retryLater возвращает песню в очередь через delay. Песня остаётся
+// отмеченной в tracked до следующей попытки.
+func (e *Enricher) retryLater(ctx context.Context, id int, delay time.Duration) {
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+			e.push(ctx, id)
+		}
+	}()
+}
+
+// process делает одну попытку дополнить песню и сохраняет её исход.
+// Возвращает true, если назначен повтор.
+func (e *Enricher) process(ctx context.Context, id int) bool {
+	fields := logrus.Fields{"song_id": id}
+
+	song, err := e.songs.Get(ctx, id)
+	if errors.Is(err, repository.ErrSongNotFound) {
+		// Песню успели удалить — дополнять нечего
+		return false
+	}
+	if err != nil {
+		logger.Log.WithError(err).WithFields(fields).Error("Failed to load song for enrichment")
+		e.retryLater(ctx, id, e.cfg.RetryDelay)
+		return true
+	}
+	if song.EnrichmentStatus != models.EnrichmentPending {
+		// Песня уже обработана: её поставили в очередь повторно
+		return false
+	}
+
+	err = e.enrich(ctx, song)
+	attempts := song.EnrichmentAttempts + 1
+	status, lastError := models.EnrichmentEnriched, ""
+	retry := false
+	switch {
+	case err == nil:
+		logger.Log.WithFields(fields).Info("Song enriched successfully")
+	case errors.Is(err, ErrCircuitOpen):
+		// API не вызывался, попытка не засчитывается
+		attempts--
+		status, lastError = models.EnrichmentPending, err.Error()
+		delay := max(e.external.Breaker().Snapshot().RetryAfter, e.cfg.RetryDelay)
+		logger.Log.WithFields(fields).WithField("delay", delay).Debug("External API is unavailable, postponing enrichment")
+		e.retryLater(ctx, id, delay)
+		retry = true
+	case permanentFailure(err) || attempts >= e.cfg.MaxAttempts:
+		status, lastError = models.EnrichmentFailed, err.Error()
+		logger.Log.WithError(err).WithFields(fields).WithField("attempts", attempts).Error("Failed to enrich song")
+	default:
+		status, lastError = models.EnrichmentPending, err.Error()
+		delay := e.retryDelay(attempts)
+		logger.Log.WithError(err).WithFields(fields).WithFields(logrus.Fields{
+			"attempts": attempts,
+			"delay":    delay,
+		}).Warn("Failed to enrich song, retrying")
+		e.retryLater(ctx, id, delay)
+		retry = true
+	}
+
+	err = e.songs.SetEnrichment(ctx, id, status, attempts, lastError)
+	if err != nil && !errors.Is(err, repository.ErrSongNotFound) {
+		logger.Log.WithError(err).WithFields(fields).Error("Failed to save enrichment status")
+	}
+	return retry
+}
+
+// retryDelay возвращает паузу после attempts неудачных попыток
+func (e *Enricher) retryDelay(attempts int) time.Duration {
+	d := e.cfg.RetryDelay
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	return d
+}
+
+// permanentFailure отличает отказы API, которые повтор не исправит, например
+// 404 для неизвестной песни
+func permanentFailure(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && negativeStatus(status.StatusCode)
+}
+
+// enrich запрашивает данные песни во внешнем API и заполняет пустые поля.
+// Правки, сделанные пользователем во время запроса, не перезаписываются:
+// при конфликте версий песня перечитывается.
+func (e *Enricher) enrich(ctx context.Context, song *models.Song) error {
+	details, err := e.external.FetchSong(ctx, song.GroupName, song.SongName)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ; i++ {
+		changed, err := e.apply(ctx, song, details)
+		if err != nil || !changed {
+			return err
+		}
+
+		err = e.songs.Update(repository.WithAuthor(ctx, enrichmentAuthor), song)
+		if errors.Is(err, repository.ErrSongNotFound) {
+			return nil
+		}
+		if !errors.Is(err, repository.ErrVersionConflict) || i == enrichConflictRetries-1 {
+			return err
+		}
+
+		logger.Log.WithFields(logrus.Fields{"song_id": song.ID}).Debug("Song changed during enrichment, reloading")
+		if song, err = e.songs.Get(ctx, song.ID); err != nil {
+			if errors.Is(err, repository.ErrSongNotFound) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// apply заполняет пустые поля песни данными API. Альбом из API используется,
+// только если у песни альбома нет.
+func (e *Enricher) apply(ctx context.Context, song, details *models.Song) (bool, error) {
+	changed := false
+	fill := func(field *string, value string) {
+		if *field == "" && value != "" {
+			*field = value
+			changed = true
+		}
+	}
+	fill(&song.ReleaseDate, details.ReleaseDate)
+	fill(&song.Lyrics, details.Lyrics)
+	fill(&song.Link, details.Link)
+
+	if song.AlbumID == nil && models.CleanName(details.Album) != "" {
+		album, err := e.albums.Resolve(ctx, song.ArtistID, details.Album, song.ReleaseDate)
+		if err != nil {
+			return false, err
+		}
+		song.AlbumID = &album.ID
+		song.Album = album.Title
+		song.TrackNumber = nil
+		if details.TrackNumber != nil && *details.TrackNumber > 0 {
+			song.TrackNumber = details.TrackNumber
+		}
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+type enricherTest struct {
+	t        *testing.T
+	ctx      context.Context
+	artists  *repository.MemoryArtistRepository
+	songs    *repository.MemorySongRepository
+	enricher *Enricher
+	// status — код ответа внешнего API
+	status   atomic.Int32
+	requests atomic.Int32
+}
+
+func newEnricherTest(t *testing.T, cfg EnricherConfig) *enricherTest {
+	t.Helper()
+
+	et := &enricherTest{t: t}
+	et.status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		et.requests.Add(1)
+		if status := int(et.status.Load()); status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(models.Song{
+			ReleaseDate: "2009-09-07",
+			Lyrics:      "Paranoia is in bloom",
+			Link:        "https://example.com/uprising",
+			Album:       "The Resistance",
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	external, err := NewExternalClient(ExternalClientConfig{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	et.artists = repository.NewMemoryArtistRepository()
+	albums := repository.NewMemoryAlbumRepository(et.artists)
+	et.songs = repository.NewMemorySongRepository(et.artists, albums)
+
+	// Отмена контекста останавливает отложенные повторы
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	et.ctx = ctx
+	et.enricher = NewEnricher(et.songs, albums, external, cfg)
+	return et
+}
+
+// addSong создаёт песню в состоянии pending
+func (et *enricherTest) addSong(name string) int {
+	et.t.Helper()
+
+	artist, err := et.artists.Resolve(et.ctx, "Muse")
+	if err != nil {
+		et.t.Fatal(err)
+	}
+	s := &models.Song{ArtistID: artist.ID, SongName: name, EnrichmentStatus: models.EnrichmentPending}
+	if err := et.songs.Create(et.ctx, s); err != nil {
+		et.t.Fatal(err)
+	}
+	return s.ID
+}
+
+func (et *enricherTest) song(id int) *models.Song {
+	et.t.Helper()
+
+	s, err := et.songs.Get(et.ctx, id)
+	if err != nil {
+		et.t.Fatal(err)
+	}
+	return s
+}
+
+func TestEnricherFillsSong(t *testing.T) {
+	et := newEnricherTest(t, EnricherConfig{QueueSize: 1})
+	id := et.addSong("Uprising")
+	version := et.song(id).Version
+
+	if et.enricher.process(et.ctx, id) {
+		t.Fatal("retry scheduled after success")
+	}
+	s := et.song(id)
+	if s.EnrichmentStatus != models.EnrichmentEnriched || s.EnrichmentAttempts != 1 || s.EnrichmentError != "" {
+		t.Errorf("enrichment = %s, %d attempts, %q", s.EnrichmentStatus, s.EnrichmentAttempts, s.EnrichmentError)
+	}
+	if s.ReleaseDate != "2009-09-07" || s.Link == "" || s.Lyrics == "" || s.Album != "The Resistance" || s.AlbumID == nil {
+		t.Errorf("song was not filled: %+v", s)
+	}
+	if s.Version <= version {
+		t.Errorf("version = %d, want > %d", s.Version, version)
+	}
+
+	// Повторная обработка готовой песни не обращается к API
+	if et.enricher.process(et.ctx, id) || et.requests.Load() != 1 {
+		t.Errorf("enriched song processed again: %d requests", et.requests.Load())
+	}
+}
+
+func TestEnricherRetriesThenFails(t *testing.T) {
+	et := newEnricherTest(t, EnricherConfig{QueueSize: 1, MaxAttempts: 2, RetryDelay: time.Hour})
+	et.status.Store(http.StatusInternalServerError)
+	id := et.addSong("Uprising")
+
+	if !et.enricher.process(et.ctx, id) {
+		t.Fatal("no retry scheduled after a server error")
+	}
+	s := et.song(id)
+	if s.EnrichmentStatus != models.EnrichmentPending || s.EnrichmentAttempts != 1 || s.EnrichmentError == "" {
+		t.Errorf("after first attempt: %s, %d attempts, %q", s.EnrichmentStatus, s.EnrichmentAttempts, s.EnrichmentError)
+	}
+
+	if et.enricher.process(et.ctx, id) {
+		t.Fatal("retry scheduled after MaxAttempts")
+	}
+	s = et.song(id)
+	if s.EnrichmentStatus != models.EnrichmentFailed || s.EnrichmentAttempts != 2 {
+		t.Errorf("after last attempt: %s, %d attempts", s.EnrichmentStatus, s.EnrichmentAttempts)
+	}
+	if s.ReleaseDate != "" {
+		t.Errorf("failed song was changed: %+v", s)
+	}
+}
+
+// 404 повтор не исправит: песня сразу получает состояние failed
+func TestEnricherFailsOnUnknownSong(t *testing.T) {
+	et := newEnricherTest(t, EnricherConfig{QueueSize: 1, MaxAttempts: 5, RetryDelay: time.Hour})
+	et.status.Store(http.StatusNotFound)
+	id := et.addSong("Unknown")
+
+	if et.enricher.process(et.ctx, id) {
+		t.Fatal("retry scheduled after 404")
+	}
+	if s := et.song(id); s.EnrichmentStatus != models.EnrichmentFailed || s.EnrichmentAttempts != 1 {
+		t.Errorf("enrichment = %s, %d attempts", s.EnrichmentStatus, s.EnrichmentAttempts)
+	}
+}
+
+func TestEnricherSkipsDeletedSong(t *testing.T) {
+	et := newEnricherTest(t, EnricherConfig{QueueSize: 1})
+	id := et.addSong("Uprising")
+	if err := et.songs.Delete(et.ctx, id, et.song(id).Version); err != nil {
+		t.Fatal(err)
+	}
+
+	if et.enricher.process(et.ctx, id) || et.requests.Load() != 0 {
+		t.Errorf("deleted song processed: %d requests", et.requests.Load())
+	}
+}
+
+func TestEnricherEnqueue(t *testing.T) {
+	et := newEnricherTest(t, EnricherConfig{QueueSize: 1})
+
+	if !et.enricher.Enqueue(1) || !et.enricher.Enqueue(1) {
+		t.Fatal("song rejected by an empty queue")
+	}
+	if len(et.enricher.queue) != 1 {
+		t.Errorf("queued song added twice: queue length %d", len(et.enricher.queue))
+	}
+	if et.enricher.Enqueue(2) {
+		t.Error("full queue accepted a song")
+	}
+
+	// Песня, не попавшая в очередь, не считается поставленной
+	<-et.enricher.queue
+	if !et.enricher.Enqueue(2) || len(et.enricher.queue) != 1 {
+		t.Error("song rejected by a full queue stays tracked")
+	}
+}
+
+func TestEnricherRequeuesPendingSongs(t *testing.T) {
+	et := newEnricherTest(t, EnricherConfig{QueueSize: 10})
+	queued := et.addSong("Uprising")
+	pending := et.addSong("Resistance")
+	done := et.addSong("Undisclosed Desires")
+	if err := et.songs.SetEnrichment(et.ctx, done, models.EnrichmentEnriched, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	et.enricher.Enqueue(queued)
+
+	et.enricher.requeuePending(et.ctx)
+	if n := len(et.enricher.queue); n != 2 {
+		t.Fatalf("queue length = %d, want 2", n)
+	}
+	got := []int{<-et.enricher.queue, <-et.enricher.queue}
+	if got[0] != queued || got[1] != pending {
+		t.Errorf("queue = %v, want [%d %d]", got, queued, pending)
+	}
+}
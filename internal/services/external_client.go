@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxExternalResponse ограничивает размер ответа внешнего API
+const maxExternalResponse = 1 << 20
+
+// ExternalClientConfig — настройки клиента внешнего API
+type ExternalClientConfig struct {
+	// BaseURL — адрес API, к которому добавляется путь /info
+	BaseURL string
+	// Timeout ограничивает одну попытку запроса
+	Timeout time.Duration
+	// Retries — число повторов после первой неудачной попытки
+	Retries int
+	// Backoff — пауза перед первым повтором; каждая следующая вдвое длиннее
+	Backoff time.Duration
+	// MaxBackoff ограничивает паузу между повторами
+	MaxBackoff time.Duration
+	// BreakerThreshold — сколько запросов подряд должно завершиться сбоем
+	// после всех повторов, чтобы автомат защиты разомкнулся; 0 отключает
+	// автомат
+	BreakerThreshold int
+	// BreakerCoolDown — сколько разомкнутый автомат отклоняет запросы
+	// перед пробным
+	BreakerCoolDown time.Duration
+	// CacheSize — число ответов API в кеше; 0 отключает кеш
+	CacheSize int
+	// CacheTTL — срок хранения найденных песен
+	CacheTTL time.Duration
+	// CacheNegativeTTL — срок хранения отказов API вроде 404; 0 не хранит их
+	CacheNegativeTTL time.Duration
+}
+
+// StatusError — ответ внешнего API с кодом, отличным от 200
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "external API error: " + e.Status
+}
+
+// ExternalClient запрашивает данные песен во внешнем API. Сетевые ошибки
+// и ответы 5xx повторяются с экспоненциальной паузой и случайным разбросом,
+// а при стабильных сбоях автомат защиты перестаёт обращаться к API.
+type ExternalClient struct {
+	cache      *SongInfoCache
+	flight     singleflight.Group
+	breaker    *CircuitBreaker
+	baseURL    *url.URL
+	http       *http.Client
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// NewExternalClient проверяет адрес API и создаёт клиент
+func NewExternalClient(cfg ExternalClientConfig) (*ExternalClient, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external API URL: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" || base.Host == "" {
+		return nil, fmt.Errorf("invalid external API URL %q: absolute http(s) URL required", cfg.BaseURL)
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 200 * time.Millisecond
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = cfg.Backoff
+	}
+	return &ExternalClient{
+		cache:      NewSongInfoCache(cfg.CacheSize, cfg.CacheTTL, cfg.CacheNegativeTTL),
+		breaker:    NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCoolDown),
+		baseURL:    base,
+		http:       &http.Client{Timeout: cfg.Timeout},
+		retries:    cfg.Retries,
+		backoff:    cfg.Backoff,
+		maxBackoff: cfg.MaxBackoff,
+	}, nil
+}
+
+// Breaker возвращает автомат защиты клиента
+func (c *ExternalClient) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// Cache возвращает кеш ответов API; nil, если кеш отключён
+func (c *ExternalClient) Cache() *SongInfoCache {
+	return c.cache
+}
+
+// FetchSong запрашивает дату релиза, текст, ссылку и альбом песни. Ответы
+// берутся из кеша, одновременные запросы одной песни объединяются в один.
+// Ожидание прерывается вместе с ctx, но общий запрос к API продолжается
+// для остальных ожидающих. Пока автомат защиты разомкнут, сразу
+// возвращает ErrCircuitOpen.
+func (c *ExternalClient) FetchSong(ctx context.Context, group, song string) (*models.Song, error) {
+	if e, ok := c.cache.get(group, song); ok {
+		logger.Log.WithFields(logrus.Fields{"group": group, "song": song, "negative": e.Negative()}).Debug("External API cache hit")
+		return e.result()
+	}
+
+	ch := c.flight.DoChan(songInfoKey(group, song), func() (interface{}, error) {
+		return c.lookup(context.WithoutCancel(ctx), group, song)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// Результат общий для всех ожидающих, каждому достаётся своя копия
+		return cloneSong(res.Val.(*models.Song)), nil
+	}
+}
+
+// lookup обращается к API через автомат защиты и кеширует ответ
+func (c *ExternalClient) lookup(ctx context.Context, group, song string) (*models.Song, error) {
+	if err := c.breaker.Allow(); err != nil {
+		logger.Log.WithFields(logrus.Fields{"group": group, "song": song}).Debug("External API circuit breaker is open, skipping request")
+		return nil, err
+	}
+	details, err := c.fetchWithRetries(ctx, group, song)
+	c.breaker.Done(err != nil && unavailable(err))
+	c.cache.put(group, song, details, err)
+	return details, err
+}
+
+func (c *ExternalClient) fetchWithRetries(ctx context.Context, group, song string) (*models.Song, error) {
+	reqURL := c.songURL(group, song)
+	fields := logrus.Fields{"group": group, "song": song, "url": reqURL}
+
+	for attempt := 0; ; attempt++ {
+		logger.Log.WithFields(fields).WithField("attempt", attempt+1).Debug("Sending request to external API")
+
+		details, err := c.fetch(ctx, reqURL)
+		if err == nil {
+			logger.Log.WithFields(logrus.Fields{"song_details": details}).Debug("Successfully fetched song details from external API")
+			return details, nil
+		}
+		if attempt >= c.retries || !retryable(ctx, err) {
+			logger.Log.WithError(err).WithFields(fields).Error("Failed to fetch song details from external API")
+			return nil, err
+		}
+
+		delay := c.delay(attempt)
+		logger.Log.WithError(err).WithFields(fields).WithFields(logrus.Fields{
+			"attempt": attempt + 1,
+			"delay":   delay,
+		}).Warn("External API request failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// songURL собирает адрес запроса, экранируя параметры. Параметры запроса
+// из BaseURL сохраняются.
+func (c *ExternalClient) songURL(group, song string) string {
+	u := c.baseURL.JoinPath("info")
+	q := u.Query()
+	q.Set("group", group)
+	q.Set("song", song)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (c *ExternalClient) fetch(ctx context.Context, reqURL string) (*models.Song, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	logger.Log.WithFields(logrus.Fields{
+		"status": resp.StatusCode,
+		"url":    reqURL,
+	}).Info("Received response from external API")
+
+	if resp.StatusCode != http.StatusOK {
+		// Тело дочитывается, чтобы соединение вернулось в пул
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxExternalResponse))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	var details models.Song
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxExternalResponse)).Decode(&details); err != nil {
+		return nil, fmt.Errorf("decode external API response: %w", err)
+	}
+	return &details, nil
+}
+
+// retryable отличает временные сбои от ошибок, которые повтор не исправит
+func retryable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && unavailable(err)
+}
+
+// unavailable сообщает, что ошибка говорит о недоступности API: сетевая
+// ошибка или ответ 5xx
+func unavailable(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// delay возвращает паузу перед повтором номер attempt: случайное значение
+// от половины до полной экспоненциальной паузы, чтобы клиенты не повторяли
+// запросы одновременно
+func (c *ExternalClient) delay(attempt int) time.Duration {
+	d := c.backoff
+	for i := 0; i < attempt && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, c.maxBackoff)
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, cfg ExternalClientConfig) *ExternalClient {
+	t.Helper()
+
+	client, err := NewExternalClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestExternalClientEscapesQuery(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/info" {
+			t.Errorf("path = %s, want /api/info", r.URL.Path)
+		}
+		got = r.URL.Query()
+		w.Write([]byte(`{"releaseDate":"1979-07-27"}`))
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, ExternalClientConfig{BaseURL: srv.URL + "/api?key=secret&lang=en"})
+	if _, err := client.FetchSong(context.Background(), "AC/DC", "Guns N' Roses & Friends?#1"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"group": "AC/DC", "song": "Guns N' Roses & Friends?#1", "key": "secret", "lang": "en"}
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("query %s = %q, want %q", k, got.Get(k), v)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("query = %v", got)
+	}
+}
+
+func TestExternalClientRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		requests int32
+	}{
+		{"server error", http.StatusBadGateway, 3},
+		{"not found", http.StatusNotFound, 1},
+		{"bad request", http.StatusBadRequest, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			client := newTestClient(t, ExternalClientConfig{BaseURL: srv.URL, Retries: 2, Backoff: time.Millisecond})
+			_, err := client.FetchSong(context.Background(), "Muse", "Uprising")
+			var status *StatusError
+			if !errors.As(err, &status) || status.StatusCode != tt.status {
+				t.Fatalf("err = %v, want status %d", err, tt.status)
+			}
+			if n := requests.Load(); n != tt.requests {
+				t.Errorf("requests = %d, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+// Оборванное соединение — сетевая ошибка, которая повторяется
+func TestExternalClientRetriesNetworkErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`{"link":"https://example.com"}`))
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, ExternalClientConfig{BaseURL: srv.URL, Retries: 2, Backoff: time.Millisecond})
+	details, err := client.FetchSong(context.Background(), "Muse", "Uprising")
+	if err != nil || details.Link != "https://example.com" {
+		t.Fatalf("FetchSong = %+v, %v", details, err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestExternalClientStopsOnCancelDuringBackoff(t *testing.T) {
+	var requests atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		// Отмена приходит, пока клиент ждёт перед повтором
+		time.AfterFunc(10*time.Millisecond, cancel)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, ExternalClientConfig{BaseURL: srv.URL, Retries: 5, Backoff: time.Hour, MaxBackoff: time.Hour})
+	start := time.Now()
+	if _, err := client.fetchWithRetries(ctx, "Muse", "Uprising"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Minute || requests.Load() != 1 {
+		t.Errorf("backoff was not interrupted: %d requests", requests.Load())
+	}
+}
+
+func TestExternalClientDelay(t *testing.T) {
+	client := newTestClient(t, ExternalClientConfig{BaseURL: "http://example.com", Backoff: 100 * time.Millisecond, MaxBackoff: time.Second})
+	for attempt := 0; attempt < 10; attempt++ {
+		d := min(100*time.Millisecond<<attempt, time.Second)
+		for i := 0; i < 100; i++ {
+			got := client.delay(attempt)
+			if got < d/2 || got > d {
+				t.Fatalf("delay(%d) = %s, want within [%s, %s]", attempt, got, d/2, d)
+			}
+		}
+	}
+}
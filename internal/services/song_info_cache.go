@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"music-library/internal/cache"
+	"music-library/internal/models"
+)
+
+// SongInfoCache хранит ответы внешнего API по нормализованным названиям
+// группы и песни. Ответ «не найдено» хранится короче, чтобы песня,
+// появившаяся в API, подхватилась быстрее. Методы nil-кеша ничего не
+// делают: кеш отключён.
+type SongInfoCache struct {
+	lru         *cache.LRU[string, CachedSongInfo]
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// CachedSongInfo — запись кеша. Отрицательная запись хранит код ответа API
+// вместо данных песни.
+type CachedSongInfo struct {
+	Group     string
+	Song      string
+	Details   *models.Song
+	Status    int
+	ExpiresAt time.Time
+}
+
+// Negative сообщает, что запись хранит отказ API
+func (e CachedSongInfo) Negative() bool {
+	return e.Details == nil
+}
+
+// result возвращает копию данных песни или сохранённый отказ API
+func (e CachedSongInfo) result() (*models.Song, error) {
+	if e.Negative() {
+		return nil, &StatusError{StatusCode: e.Status, Status: strconv.Itoa(e.Status) + " " + http.StatusText(e.Status)}
+	}
+	return cloneSong(e.Details), nil
+}
+
+// NewSongInfoCache создаёт кеш на size записей; size <= 0 или ttl <= 0
+// отключают кеш
+func NewSongInfoCache(size int, ttl, negativeTTL time.Duration) *SongInfoCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &SongInfoCache{lru: cache.NewLRU[string, CachedSongInfo](size), ttl: ttl, negativeTTL: negativeTTL}
+}
+
+// songInfoKey — ключ кеша: регистр и лишние пробелы не различаются
+func songInfoKey(group, song string) string {
+	return models.NormalizeName(group) + "\x00" + models.NormalizeName(song)
+}
+
+// get возвращает действующую запись песни
+func (c *SongInfoCache) get(group, song string) (CachedSongInfo, bool) {
+	if c == nil {
+		return CachedSongInfo{}, false
+	}
+	return c.lru.Get(songInfoKey(group, song), time.Now())
+}
+
+// put сохраняет ответ API. Сохраняются только успешные ответы и отказы 4xx:
+// сбои сети и 5xx временные и кешироваться не должны.
+func (c *SongInfoCache) put(group, song string, details *models.Song, err error) {
+	if c == nil {
+		return
+	}
+	e := CachedSongInfo{Group: models.CleanName(group), Song: models.CleanName(song)}
+	ttl := c.ttl
+	if err != nil {
+		var status *StatusError
+		if !errors.As(err, &status) || !negativeStatus(status.StatusCode) || c.negativeTTL <= 0 {
+			return
+		}
+		e.Status = status.StatusCode
+		ttl = c.negativeTTL
+	} else {
+		e.Details = cloneSong(details)
+	}
+	now := time.Now()
+	e.ExpiresAt = now.Add(ttl)
+	c.lru.Set(songInfoKey(group, song), e, ttl, now)
+}
+
+// Entries возвращает действующие записи от недавно использованных к давним
+func (c *SongInfoCache) Entries() []CachedSongInfo {
+	if c == nil {
+		return nil
+	}
+	entries := c.lru.Entries(time.Now())
+	infos := make([]CachedSongInfo, len(entries))
+	for i, e := range entries {
+		infos[i] = e.Value
+	}
+	return infos
+}
+
+// Forget удаляет запись песни и сообщает, была ли она в кеше
+func (c *SongInfoCache) Forget(group, song string) bool {
+	if c == nil {
+		return false
+	}
+	return c.lru.Delete(songInfoKey(group, song))
+}
+
+// Flush очищает кеш и возвращает число удалённых записей
+func (c *SongInfoCache) Flush() int {
+	if c == nil {
+		return 0
+	}
+	return c.lru.Flush()
+}
+
+// Stats возвращает счётчики кеша
+func (c *SongInfoCache) Stats() cache.Stats {
+	if c == nil {
+		return cache.Stats{}
+	}
+	return c.lru.Stats()
+}
+
+// Len возвращает число записей
+func (c *SongInfoCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.lru.Len()
+}
+
+// Capacity возвращает наибольшее число записей; 0 — кеш отключён
+func (c *SongInfoCache) Capacity() int {
+	if c == nil {
+		return 0
+	}
+	return c.lru.Capacity()
+}
+
+// negativeStatus отбирает отказы API, которые повторный запрос не изменит
+func negativeStatus(code int) bool {
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+// cloneSong копирует данные песни, чтобы вызывающий код не менял запись кеша
+func cloneSong(s *models.Song) *models.Song {
+	c := *s
+	if s.TrackNumber != nil {
+		n := *s.TrackNumber
+		c.TrackNumber = &n
+	}
+	return &c
+}
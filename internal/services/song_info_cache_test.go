@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"music-library/internal/models"
+)
+
+func TestSongInfoCacheStoresSuccessAndNegativeAnswers(t *testing.T) {
+	c := NewSongInfoCache(10, time.Hour, 20*time.Millisecond)
+
+	details := &models.Song{ReleaseDate: "2009-09-07", Link: "https://example.com"}
+	c.put("Muse", "Uprising", details, nil)
+	details.Link = "changed"
+
+	// Ключ не зависит от регистра и лишних пробелов; запись — копия
+	e, ok := c.get("  muse ", "UPRISING")
+	if !ok || e.Negative() {
+		t.Fatalf("get = %+v, %v", e, ok)
+	}
+	if got, _ := e.result(); got.Link != "https://example.com" {
+		t.Errorf("cached details changed with the original: %q", got.Link)
+	}
+
+	c.put("Muse", "Unknown", nil, &StatusError{StatusCode: http.StatusNotFound, Status: "404 Not Found"})
+	e, ok = c.get("Muse", "Unknown")
+	if !ok || !e.Negative() {
+		t.Fatalf("negative entry = %+v, %v", e, ok)
+	}
+	var status *StatusError
+	if _, err := e.result(); !errors.As(err, &status) || status.StatusCode != http.StatusNotFound {
+		t.Errorf("negative result = %v, want 404", err)
+	}
+
+	// Отрицательная запись живёт negativeTTL, положительная — ttl
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.get("Muse", "Unknown"); ok {
+		t.Error("negative entry outlived negativeTTL")
+	}
+	if _, ok := c.get("Muse", "Uprising"); !ok {
+		t.Error("positive entry expired with negativeTTL")
+	}
+}
+
+func TestSongInfoCacheSkipsTransientFailures(t *testing.T) {
+	c := NewSongInfoCache(10, time.Hour, time.Hour)
+	for _, err := range []error{
+		&StatusError{StatusCode: http.StatusInternalServerError},
+		&StatusError{StatusCode: http.StatusTooManyRequests},
+		&StatusError{StatusCode: http.StatusRequestTimeout},
+		context.DeadlineExceeded,
+	} {
+		c.put("Muse", "Uprising", nil, err)
+		if _, ok := c.get("Muse", "Uprising"); ok {
+			t.Errorf("transient failure %v was cached", err)
+		}
+	}
+
+	// Без negativeTTL отказы не хранятся
+	c = NewSongInfoCache(10, time.Hour, 0)
+	c.put("Muse", "Unknown", nil, &StatusError{StatusCode: http.StatusNotFound})
+	if c.Len() != 0 {
+		t.Error("negative entry cached with negativeTTL = 0")
+	}
+}
+
+func TestDisabledSongInfoCache(t *testing.T) {
+	c := NewSongInfoCache(0, time.Hour, time.Hour)
+	if c != nil {
+		t.Fatal("cache with size 0 is enabled")
+	}
+	c.put("Muse", "Uprising", &models.Song{}, nil)
+	if _, ok := c.get("Muse", "Uprising"); ok || c.Len() != 0 || c.Flush() != 0 || c.Entries() != nil {
+		t.Error("nil cache stored an entry")
+	}
+}
+
+func TestExternalClientUsesCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("song") == "Unknown" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(models.Song{ReleaseDate: "2009-09-07"})
+	}))
+	defer srv.Close()
+
+	client, err := NewExternalClient(ExternalClientConfig{BaseURL: srv.URL, CacheSize: 10, CacheTTL: time.Hour, CacheNegativeTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		got, err := client.FetchSong(ctx, "Muse", "Uprising")
+		if err != nil || got.ReleaseDate != "2009-09-07" {
+			t.Fatalf("FetchSong = %+v, %v", got, err)
+		}
+		if _, err := client.FetchSong(ctx, "Muse", "Unknown"); err == nil {
+			t.Fatal("unknown song found")
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want one per song", n)
+	}
+	if s := client.Cache().Stats(); s.Hits != 4 {
+		t.Errorf("cache hits = %d, want 4", s.Hits)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TrashPurger периодически удаляет навсегда песни, пролежавшие в корзине
+// дольше срока хранения
+type TrashPurger struct {
+	songs     repository.SongRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(songs repository.SongRepository, retention, interval time.Duration) *TrashPurger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TrashPurger{songs: songs, retention: retention, interval: interval}
+}
+
+// Run очищает корзину сразу и затем каждые interval, пока не отменён ctx
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge(ctx context.Context) {
+	before := time.Now().Add(-p.retention)
+	purged, err := p.songs.PurgeTrashed(ctx, before)
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to purge trashed songs")
+		return
+	}
+	if purged > 0 {
+		logger.Log.WithFields(logrus.Fields{"purged": purged, "before": before}).Info("Purged trashed songs")
+	}
+}
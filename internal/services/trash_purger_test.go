@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"music-library/internal/logger"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	logger.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestTrashPurgerRemovesOnlyExpiredSongs(t *testing.T) {
+	ctx := context.Background()
+	artists := repository.NewMemoryArtistRepository()
+	albums := repository.NewMemoryAlbumRepository(artists)
+	songs := repository.NewMemorySongRepository(artists, albums)
+	artist, err := artists.Resolve(ctx, "Muse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(name string) int {
+		s := &models.Song{ArtistID: artist.ID, SongName: name}
+		if err := songs.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+		return s.ID
+	}
+
+	const retention = 50 * time.Millisecond
+	old, recent, kept := add("Uprising"), add("Hysteria"), add("Madness")
+	if err := songs.Delete(ctx, old, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(retention + 10*time.Millisecond)
+	if err := songs.Delete(ctx, recent, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	NewTrashPurger(songs, retention, time.Hour).purge(ctx)
+
+	if err := songs.Restore(ctx, old); !errors.Is(err, repository.ErrSongNotFound) {
+		t.Errorf("expired song was not purged: %v", err)
+	}
+	trash, err := songs.List(ctx, repository.SongFilter{Trash: repository.OnlyTrashed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != recent {
+		t.Errorf("trash = %+v, want only the recently deleted song", trash)
+	}
+	if _, err := songs.Get(ctx, kept); err != nil {
+		t.Errorf("song outside trash was purged: %v", err)
+	}
+}
+
+func TestTrashPurgerRunStopsWithContext(t *testing.T) {
+	artists := repository.NewMemoryArtistRepository()
+	songs := repository.NewMemorySongRepository(artists, repository.NewMemoryAlbumRepository(artists))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewTrashPurger(songs, time.Hour, time.Millisecond).Run(ctx)
+		close(done)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
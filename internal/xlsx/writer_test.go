@@ -0,0 +1,140 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// sheet — лист в том виде, в каком его читает encoding/xml
+type sheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R      string `xml:"r,attr"`
+			T      string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readBook распаковывает книгу и проверяет, что каждая часть — корректный XML
+func readBook(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not valid XML: %v", f.Name, err)
+			}
+		}
+		parts[f.Name] = body
+	}
+	return parts
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, `Songs & <"Hits">`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow("id", "name", nil, "note"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(42, "AC/DC & <Friends>", nil, "tab\there\x01bell\x0bvt\nline"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(1.5); err == nil {
+		t.Error("unsupported value accepted")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parts := readBook(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("book has no %s", name)
+		}
+	}
+
+	var book struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(parts["xl/workbook.xml"], &book); err != nil || len(book.Sheets) != 1 || book.Sheets[0].Name != `Songs & <"Hits">` {
+		t.Errorf("workbook sheets = %+v, %v", book.Sheets, err)
+	}
+
+	var s sheet
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Rows) < 2 || s.Rows[1].R != "2" {
+		t.Fatalf("rows = %+v", s.Rows)
+	}
+	cells := s.Rows[1].Cells
+	if len(cells) != 3 || cells[0].R != "A2" || cells[0].Value != "42" || cells[1].R != "B2" || cells[2].R != "D2" {
+		t.Fatalf("cells = %+v", cells)
+	}
+	if cells[1].T != "inlineStr" || cells[1].Inline != "AC/DC & <Friends>" {
+		t.Errorf("escaped text = %q", cells[1].Inline)
+	}
+	// Управляющие символы, запрещённые в XML, заменяются, а не ломают файл
+	if got := cells[2].Inline; got != "tab\there�bell�vt\nline" {
+		t.Errorf("control characters = %q", got)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA", 16383: "XFD"}
+	for i, want := range tests {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if s := strings.Repeat("a", MaxCellLength); truncate(s) != s {
+		t.Error("text at the limit was truncated")
+	}
+	if got := truncate(strings.Repeat("a", MaxCellLength+1)); len(got) != MaxCellLength {
+		t.Errorf("len = %d, want %d", len(got), MaxCellLength)
+	}
+
+	// Кириллица: байт вдвое больше символов, обрезка по символам
+	got := truncate(strings.Repeat("я", MaxCellLength+1))
+	if n := len([]rune(got)); n != MaxCellLength {
+		t.Errorf("cyrillic: %d runes, want %d", n, MaxCellLength)
+	}
+
+	// Символ вне BMP занимает две единицы UTF-16 и не разрезается
+	got = truncate(strings.Repeat("a", MaxCellLength-1) + "🎵" + "b")
+	if got != strings.Repeat("a", MaxCellLength-1) {
+		t.Errorf("emoji at the limit: len %d", len(got))
+	}
+}
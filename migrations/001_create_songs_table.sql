@@ -1,4 +1,4 @@
-CREATE TABLE songs (
+CREATE TABLE IF NOT EXISTS songs (
     id SERIAL PRIMARY KEY,
     group_name TEXT NOT NULL,
     song_name TEXT NOT NULL,
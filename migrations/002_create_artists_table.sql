@@ -0,0 +1,29 @@
+CREATE TABLE artists (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    normalized_name TEXT NOT NULL UNIQUE
+);
+
+-- Дедупликация: "Muse", "muse " и "MUSE" сводятся к одному исполнителю,
+-- отображаемое имя берём из самой ранней песни
+INSERT INTO artists (name, normalized_name)
+SELECT DISTINCT ON (normalized) display, normalized
+FROM (
+    SELECT id,
+           regexp_replace(btrim(group_name), '\s+', ' ', 'g') AS display,
+           lower(regexp_replace(btrim(group_name), '\s+', ' ', 'g')) AS normalized
+    FROM songs
+) s
+ORDER BY normalized, id;
+
+ALTER TABLE songs ADD COLUMN artist_id INTEGER REFERENCES artists (id);
+
+UPDATE songs s
+SET artist_id = a.id
+FROM artists a
+WHERE a.normalized_name = lower(regexp_replace(btrim(s.group_name), '\s+', ' ', 'g'));
+
+ALTER TABLE songs ALTER COLUMN artist_id SET NOT NULL;
+ALTER TABLE songs DROP COLUMN group_name;
+
+CREATE INDEX songs_artist_id_idx ON songs (artist_id);
@@ -0,0 +1,5 @@
+ALTER TABLE songs
+    DROP COLUMN track_number,
+    DROP COLUMN album_id;
+
+DROP TABLE albums;
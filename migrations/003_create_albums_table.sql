@@ -0,0 +1,14 @@
+CREATE TABLE albums (
+    id SERIAL PRIMARY KEY,
+    artist_id INTEGER NOT NULL REFERENCES artists (id),
+    title TEXT NOT NULL,
+    normalized_title TEXT NOT NULL,
+    release_date DATE,
+    UNIQUE (artist_id, normalized_title)
+);
+
+ALTER TABLE songs
+    ADD COLUMN album_id INTEGER REFERENCES albums (id) ON DELETE SET NULL,
+    ADD COLUMN track_number INTEGER CHECK (track_number > 0);
+
+CREATE INDEX songs_album_id_idx ON songs (album_id, track_number);
@@ -0,0 +1,17 @@
+CREATE TABLE song_verses (
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    PRIMARY KEY (song_id, position)
+);
+
+-- Куплеты разделяются пустыми строками; нумерация с нуля, как в models.ParseVerses
+INSERT INTO song_verses (song_id, position, text)
+SELECT song_id, (row_number() OVER (PARTITION BY song_id ORDER BY ord) - 1)::int, text
+FROM (
+    SELECT s.id AS song_id, v.ord, btrim(v.text, E' \t\n') AS text
+    FROM songs s,
+         regexp_split_to_table(replace(s.lyrics, E'\r\n', E'\n'), E'\n[ \t]*\n') WITH ORDINALITY AS v (text, ord)
+    WHERE s.lyrics IS NOT NULL
+) parts
+WHERE text <> '';
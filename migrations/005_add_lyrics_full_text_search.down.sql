@@ -0,0 +1,8 @@
+DROP TRIGGER song_verses_search_vector_trigger ON song_verses;
+DROP TRIGGER songs_search_vector_trigger ON songs;
+
+ALTER TABLE song_verses DROP COLUMN search_vector;
+ALTER TABLE songs DROP COLUMN search_vector;
+
+DROP FUNCTION song_verses_search_vector_update();
+DROP FUNCTION songs_search_vector_update();
@@ -0,0 +1,37 @@
+-- Тексты песен бывают и на русском, и на английском, поэтому документ
+-- индексируется обеими конфигурациями: запрос любой из них найдёт совпадение
+CREATE FUNCTION songs_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('russian', coalesce(NEW.song_name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.song_name, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(NEW.lyrics, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.lyrics, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION song_verses_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := to_tsvector('russian', NEW.text) || to_tsvector('english', NEW.text);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE songs ADD COLUMN search_vector tsvector;
+ALTER TABLE song_verses ADD COLUMN search_vector tsvector;
+
+CREATE TRIGGER songs_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF song_name, lyrics ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_search_vector_update();
+
+CREATE TRIGGER song_verses_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF text ON song_verses
+    FOR EACH ROW EXECUTE FUNCTION song_verses_search_vector_update();
+
+-- Заполняем векторы для уже существующих строк через триггеры
+UPDATE songs SET lyrics = lyrics;
+UPDATE song_verses SET text = text;
+
+CREATE INDEX songs_search_vector_idx ON songs USING GIN (search_vector);
+CREATE INDEX song_verses_search_vector_idx ON song_verses USING GIN (search_vector);
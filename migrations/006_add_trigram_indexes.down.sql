@@ -0,0 +1,2 @@
+DROP INDEX songs_song_name_trgm_idx;
+DROP INDEX artists_name_trgm_idx;
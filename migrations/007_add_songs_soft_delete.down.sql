@@ -0,0 +1,6 @@
+DROP INDEX songs_deleted_at_idx;
+
+-- Без колонки deleted_at песни из корзины снова стали бы видимыми
+DELETE FROM songs WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE songs DROP COLUMN deleted_at;
@@ -0,0 +1,4 @@
+ALTER TABLE songs ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Корзина и фоновая очистка выбирают только удалённые песни
+CREATE INDEX songs_deleted_at_idx ON songs (deleted_at) WHERE deleted_at IS NOT NULL;
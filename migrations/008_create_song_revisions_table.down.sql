@@ -0,0 +1,2 @@
+DROP TABLE song_revisions;
+DROP FUNCTION song_revisions_immutable();
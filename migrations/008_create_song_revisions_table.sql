@@ -0,0 +1,40 @@
+-- Каждое создание, изменение, удаление и восстановление песни сохраняет
+-- полный снимок песни. Снимки удаляются только вместе с песней при очистке корзины.
+CREATE TABLE song_revisions (
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    author TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    snapshot JSONB NOT NULL,
+    PRIMARY KEY (song_id, revision)
+);
+
+CREATE FUNCTION song_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'song revisions are immutable';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER song_revisions_immutable_trigger
+    BEFORE UPDATE ON song_revisions
+    FOR EACH ROW EXECUTE FUNCTION song_revisions_immutable();
+
+-- Начальная правка для уже существующих песен; снимок совпадает с JSON models.Song
+INSERT INTO song_revisions (song_id, revision, action, author, snapshot)
+SELECT s.id, 1, 'create', 'system', jsonb_strip_nulls(jsonb_build_object(
+    'id', s.id,
+    'artistId', s.artist_id,
+    'group', a.name,
+    'song', s.song_name,
+    'albumId', s.album_id,
+    'album', al.title,
+    'trackNumber', s.track_number,
+    'releaseDate', COALESCE(s.release_date::text, ''),
+    'lyrics', COALESCE(s.lyrics, ''),
+    'link', COALESCE(s.link, ''),
+    'deletedAt', s.deleted_at
+))
+FROM songs s
+JOIN artists a ON a.id = s.artist_id
+LEFT JOIN albums al ON al.id = s.album_id;
@@ -0,0 +1,3 @@
+-- Версия песни для оптимистичных блокировок: увеличивается при каждом изменении
+-- и отдаётся клиентам как ETag
+ALTER TABLE songs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+DROP INDEX songs_updated_at_idx;
+
+ALTER TABLE songs
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at;
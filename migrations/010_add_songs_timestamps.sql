@@ -0,0 +1,16 @@
+ALTER TABLE songs
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Для уже существующих песен время берётся из истории правок
+UPDATE songs s
+SET created_at = r.first_at, updated_at = r.last_at
+FROM (
+    SELECT song_id, min(created_at) AS first_at, max(created_at) AS last_at
+    FROM song_revisions
+    GROUP BY song_id
+) r
+WHERE r.song_id = s.id;
+
+-- Last-Modified списка песен — время последнего изменения любой песни
+CREATE INDEX songs_updated_at_idx ON songs (updated_at);
@@ -0,0 +1,3 @@
+DROP TABLE playlist_entries;
+DROP FUNCTION playlist_entries_renumber();
+DROP TABLE playlists;
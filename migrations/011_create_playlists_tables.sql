@@ -0,0 +1,45 @@
+CREATE TABLE playlists (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Позиции в плейлисте идут подряд с 1. Уникальность проверяется в конце
+-- транзакции, чтобы перемещение могло сдвигать соседние записи.
+-- Одна песня может входить в плейлист несколько раз.
+CREATE TABLE playlist_entries (
+    id SERIAL PRIMARY KEY,
+    playlist_id INTEGER NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position > 0),
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT playlist_entries_position_key UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX playlist_entries_song_id_idx ON playlist_entries (song_id);
+
+-- После удаления записей, в том числе каскадного при очистке корзины,
+-- позиции оставшихся записей сдвигаются, чтобы не было пропусков
+CREATE FUNCTION playlist_entries_renumber() RETURNS trigger AS $$
+BEGIN
+    UPDATE playlist_entries e
+    SET position = n.position
+    FROM (
+        SELECT id, row_number() OVER (PARTITION BY playlist_id ORDER BY position) AS position
+        FROM playlist_entries
+        WHERE playlist_id IN (SELECT playlist_id FROM removed)
+    ) n
+    WHERE e.id = n.id AND e.position <> n.position;
+
+    UPDATE playlists SET updated_at = now()
+    WHERE id IN (SELECT playlist_id FROM removed);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER playlist_entries_renumber_trigger
+    AFTER DELETE ON playlist_entries
+    REFERENCING OLD TABLE AS removed
+    FOR EACH STATEMENT EXECUTE FUNCTION playlist_entries_renumber();
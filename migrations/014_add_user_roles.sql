@@ -0,0 +1,22 @@
+-- До появления ролей любой пользователь мог изменять библиотеку, поэтому
+-- существующие пользователи становятся редакторами, а самый первый — админом.
+-- Новые пользователи регистрируются читателями.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'reader'
+    CHECK (role IN ('reader', 'editor', 'moderator', 'admin'));
+
+UPDATE users SET role = 'editor';
+UPDATE users SET role = 'admin' WHERE id = (SELECT min(id) FROM users);
+
+-- Журнал смены ролей не ссылается на users, чтобы переживать удаление пользователей
+CREATE TABLE role_changes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    old_role TEXT NOT NULL,
+    new_role TEXT NOT NULL,
+    changed_by_id INTEGER NOT NULL,
+    changed_by_username TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX role_changes_user_id_idx ON role_changes (user_id);
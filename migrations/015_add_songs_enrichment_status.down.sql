@@ -0,0 +1,6 @@
+DROP INDEX songs_enrichment_pending_idx;
+
+ALTER TABLE songs
+    DROP COLUMN enrichment_status,
+    DROP COLUMN enrichment_error,
+    DROP COLUMN enrichment_attempts;
@@ -0,0 +1,10 @@
+-- Состояние фонового дополнения песни данными внешнего API. У песен,
+-- добавленных до появления очереди, состояния нет.
+ALTER TABLE songs
+    ADD COLUMN enrichment_status TEXT
+        CHECK (enrichment_status IN ('pending', 'enriched', 'failed')),
+    ADD COLUMN enrichment_error TEXT,
+    ADD COLUMN enrichment_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- После перезапуска ожидающие песни снова ставятся в очередь
+CREATE INDEX songs_enrichment_pending_idx ON songs (id) WHERE enrichment_status = 'pending';
@@ -0,0 +1,9 @@
+// Package migrations встраивает SQL-миграции в бинарник
+package migrations
+
+import "embed"
+
+// FS содержит файлы вида NNN_name.sql (up) и NNN_name.down.sql (down)
+//
+//go:embed *.sql
+var FS embed.FS